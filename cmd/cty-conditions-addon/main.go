@@ -10,239 +10,836 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/sourcehawk/cty-generator-addons/internal/aggregate"
+	"github.com/sourcehawk/cty-generator-addons/internal/catalog"
+	crdm "github.com/sourcehawk/cty-generator-addons/internal/crd_manifests"
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"github.com/sourcehawk/cty-generator-addons/internal/export"
+	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
 	hrend "github.com/sourcehawk/cty-generator-addons/internal/html/renderers"
+	"github.com/sourcehawk/cty-generator-addons/internal/languages"
+	md "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+	mdrend "github.com/sourcehawk/cty-generator-addons/internal/markdown/renderers"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
 	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	cns "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/comment_normalizers"
 	lhs "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/line_handlers"
 	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+	"github.com/sourcehawk/cty-generator-addons/internal/textdiff"
 	"golang.org/x/net/html"
 )
 
-// -------- Domain types --------
-
-type ReasonDoc struct {
-	Name        string // string literal value (e.g. "CreationError")
-	ConstName   string // Go const identifier
-	Description string // from comments
-}
-
-type ConditionDoc struct {
-	Name        string // string literal value (e.g. "Ready", "EncryptionReady")
-	ConstName   string // Go const identifier
-	Description string
-	Reasons     []ReasonDoc
-}
-
-type CRD struct {
-	Name       string
-	Conditions []ConditionDoc
-}
-
 func main() {
 	path := flag.String("path", ".", "root directory to scan (recursively)")
 	title := flag.String("title", "Conditions Reference", "Section title")
-	injectPath := flag.String("inject-into", "index.html", "CTY index.html to modify in-place (append to last .content)")
+	injectPath := flag.String("inject-into", "index.html", "CTY index.html to modify in-place, at -target")
+	targetSelector := flag.String(
+		"target", "div.content", "CSS selector of the element in -inject-into to inject at, the last match is used",
+	)
+	placement := flag.String(
+		"placement", "append", "where to inject relative to -target: append, prepend, before, after or replace",
+	)
+	targetFirst := flag.Bool("target-first", false, "use the first element matching -target instead of the last")
+	perCRD := flag.Bool(
+		"per-crd", false, "inject the conditions of every CRD into the block cty renders for it instead of one section",
+	)
+	crdBlock := flag.String("crd-block", ".card", "CSS selector of the blocks cty renders per CRD, for -per-crd")
+	crdHeader := flag.String(
+		"crd-header", ".card-header, h1, h2, h3, h4", "CSS selector of the header naming the Kind of a -crd-block",
+	)
+	crdPlacement := flag.String(
+		"crd-placement", "append", "where to inject relative to -crd-block: append, prepend, before or after",
+	)
+	allowedTypes := flag.Bool(
+		"allowed-types", false, "list the documented conditions of every CRD at its -property-path in the cty schema",
+	)
+	propertyPath := flag.String(
+		"property-path", "status.conditions", "dot separated path of the property -allowed-types lists at",
+	)
+	propertyNode := flag.String("property", ".accordion-item", "CSS selector of the node cty renders per schema property")
+	propertyName := flag.String("property-name", ".property-name", "CSS selector of the name of a -property")
+	crdFolder := flag.String("crd-folder", "", "folder with the CRD manifests given to cty, validates the tags against them")
+	load := flag.String(
+		"load", "types", "how Go packages are loaded: types (resolve constant values) or syntax (string literals only)",
+	)
+	diagFormat := flag.String("diagnostics-format", "text", "format of the reported problems: text, json or sarif")
+	diagOut := flag.String("diagnostics-out", "", "file to write the reported problems to (default stderr)")
+	sourceURL := flag.String(
+		"source-url", "", "URL template linking to the Go declarations, e.g. https://github.com/org/repo/blob/main/{file}#L{line}",
+	)
+	sourceRoot := flag.String("source-root", ".", "directory that {file} in -source-url is relative to")
+	stripIdent := flag.Bool(
+		"strip-leading-ident", true, "remove the identifier doc comments conventionally start with from descriptions",
+	)
+	var stripPatterns stringsFlag
+	flag.Var(&stripPatterns, "strip-pattern", "regular expression removed from descriptions, can be repeated")
+	var catalogs stringsFlag
+	flag.Var(&catalogs, "catalog", "YAML or JSON catalog of conditions not declared in the sources, can be repeated")
+	exportFormat := flag.String(
+		"export", "", "write the documented conditions as json or yaml instead of injecting HTML",
+	)
+	exportOut := flag.String("export-out", "", "file to write the -export document to (default stdout)")
+	format := flag.String("format", "html", "output format: html (injected into -inject-into) or markdown")
+	out := flag.String("out", "", "file to write the -format markdown or -standalone output to (default stdout)")
+	standalone := flag.Bool(
+		"standalone", false, "write a self-contained HTML page with the CTY styles instead of injecting into -inject-into",
+	)
+	var markdownTargets stringsFlag
+	flag.Var(
+		&markdownTargets, "inject-markdown",
+		"Markdown file, or directory of them, to update between cty-conditions markers, can be repeated",
+	)
+	check := flag.Bool(
+		"check", false, "write nothing, print a diff and fail if the generated files are not up to date",
+	)
+	dryRun := flag.Bool("dry-run", false, "write nothing, print the rendered fragments to stdout")
+	showDiff := flag.Bool("diff", false, "write nothing, print how the target files would change")
+	langNames := flag.String(
+		"languages", "go", "comma separated languages of the sources to scan: go, python and rust",
+	)
 
 	flag.Parse()
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	loadMode := tp.LoadTypes
+	switch *load {
+	case "types":
+	case "syntax":
+		loadMode = tp.LoadSyntax
+	default:
+		failf("invalid -load %q, expected types or syntax", *load)
+	}
+	switch *format {
+	case "html", "markdown":
+	default:
+		failf("invalid -format %q, expected html or markdown", *format)
+	}
+	if *standalone && *format != "html" {
+		failf("-standalone requires -format html")
+	}
+	mode := writeFiles
+	for _, m := range []struct {
+		set  bool
+		mode writeMode
+	}{{*check, checkFiles}, {*showDiff, diffFiles}, {*dryRun, printFragments}} {
+		if m.set && mode != writeFiles {
+			failf("-check, -diff and -dry-run cannot be combined")
+		}
+		if m.set {
+			mode = m.mode
+		}
+	}
+	if mode == checkFiles || mode == diffFiles {
+		stdoutOutput := *exportFormat == "" && len(markdownTargets) == 0 && (*format == "markdown" || *standalone)
+		if *out == "" && stdoutOutput {
+			failf("-check and -diff compare the generated files, set -out")
+		}
+		if *exportFormat != "" && *exportOut == "" {
+			failf("-check and -diff compare the generated files, set -export-out")
+		}
+	}
+	sel, err := hr.ParseSelector(*targetSelector)
+	if err != nil {
+		failf("invalid -target: %v", err)
+	}
+	place, err := hr.ParsePlacement(*placement)
+	if err != nil {
+		failf("invalid -placement: %v", err)
+	}
+	target := hr.Target{Selector: sel, Placement: place, First: *targetFirst}
+	if (*perCRD || *allowedTypes) && (*format != "html" || *standalone) {
+		failf("-per-crd and -allowed-types inject into -inject-into, they cannot be combined with -format markdown " +
+			"or -standalone")
+	}
+	var crdTargets hr.CRDTargets
+	if crdTargets.Block, err = hr.ParseSelector(*crdBlock); err != nil {
+		failf("invalid -crd-block: %v", err)
+	}
+	if crdTargets.Header, err = hr.ParseSelector(*crdHeader); err != nil {
+		failf("invalid -crd-header: %v", err)
+	}
+	if crdTargets.Placement, err = hr.ParsePlacement(*crdPlacement); err != nil || crdTargets.Placement == hr.Replace {
+		failf("invalid -crd-placement %q, expected append, prepend, before or after", *crdPlacement)
+	}
+	propTargets := hr.PropertyTargets{Path: strings.Split(*propertyPath, ".")}
+	if propTargets.Property, err = hr.ParseSelector(*propertyNode); err != nil {
+		failf("invalid -property: %v", err)
+	}
+	if propTargets.Name, err = hr.ParseSelector(*propertyName); err != nil {
+		failf("invalid -property-name: %v", err)
+	}
+	switch *exportFormat {
+	case "", export.FormatJSON, export.FormatYAML:
+	default:
+		failf("invalid -export %q, expected json or yaml", *exportFormat)
+	}
+	switch *diagFormat {
+	case diagnostics.FormatText, diagnostics.FormatJSON, diagnostics.FormatSARIF:
+	default:
+		failf("invalid -diagnostics-format %q, expected text, json or sarif", *diagFormat)
+	}
 
-	fp := tp.NewFileTagParser(
-		[]tp.DocTagParser{tps.ConditionTagParser{}, tps.ReasonTagParser{}},
-		lhs.GoLineCommentMatcher{},
-		lhs.GoLineCommentTrimmer{},
-		lhs.GoLineCommentBulletPointMatcher{},
-	)
+	// Descriptions are the doc comments without the lines meant for tools, and without whatever the
+	// user asked to strip.
+	normalizer := tp.NormalizerPipeline{cns.MarkerLineDropper{}}
+	if *stripIdent {
+		normalizer = append(normalizer, cns.LeadingIdentStripper{})
+	}
+	if len(stripPatterns) > 0 {
+		ps, err := cns.NewPatternStripper(stripPatterns)
+		if err != nil {
+			failf("invalid -strip-pattern: %v", err)
+		}
+		normalizer = append(normalizer, ps)
+	}
+
+	langs, err := languages.ByName(strings.Split(*langNames, ","))
+	if err != nil {
+		failf("invalid -languages: %v", err)
+	}
+	registry, err := languages.NewRegistry(langs...)
+	if err != nil {
+		failf("invalid -languages: %v", err)
+	}
 
-	// Walk repo and collect all tag results.
+	tagParsers := []tp.DocTagParser{tps.ConditionTagParser{}, tps.ReasonTagParser{}}
+	gp := tp.NewGoTagParser(tagParsers, lhs.GoCommentTrimmer{}, normalizer, loadMode)
+	fileParsers := map[string]*tp.FileDocTagParser{}
+
+	// Walk repo and collect all tag results. Problems are collected rather than returned so that a
+	// single run reports all of them.
+	diags := diagnostics.NewCollector()
 	var all []*tp.DocTagResult
-	err := filepath.WalkDir(*path, func(p string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(*path, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return err
+			diags.Errorf(diagnostics.Position{File: p}, "%v", err)
+			return nil
 		}
-		if d.IsDir() {
-			base := filepath.Base(p)
-			if base == "vendor" || strings.HasPrefix(base, ".") {
-				return filepath.SkipDir
+		if !d.IsDir() {
+			// Go is parsed per package below, other languages file by file.
+			lang, ok := registry.Lookup(p)
+			if !ok || lang.Name == languages.Go.Name {
+				return nil
 			}
+			if fileParsers[lang.Name] == nil {
+				fileParsers[lang.Name] = lang.TagParser(tagParsers, normalizer)
+			}
+			all = append(all, fileParsers[lang.Name].ParseTags(p, diags)...)
 			return nil
 		}
-		if strings.HasSuffix(p, ".go") && !strings.HasSuffix(p, "_test.go") {
-			res, perr := fp.ParseTags(p)
-			if perr != nil {
-				return perr
-			}
-			all = append(all, res...)
+		if p != *path && skipDir(p) {
+			return filepath.SkipDir
+		}
+		if registry.Has(languages.Go.Name) {
+			all = append(all, gp.ParsePackage(p, diags)...)
 		}
 		return nil
 	})
 	if err != nil {
-		failf("walk error: %v", err)
+		diags.Errorf(diagnostics.Position{File: *path}, "%v", err)
+	}
+
+	// Catalog entries come after the tags, so that the sources take precedence.
+	for _, c := range catalogs {
+		all = append(all, catalog.LoadFile(c, diags)...)
 	}
 
 	// Aggregate into CRD -> Conditions -> Reasons
-	crds := buildCRDConditionsFromResults(all)
+	crds := aggregate.Build(all, diags)
 
-	// Build the component tree and render
-	section := hrend.NewSectionNode(*title)
-	for _, crd := range crds {
-		crdNode := hrend.NewCRDNode(crd.Name)
+	// Validated before the files are made relative to -source-root, so that the problems point at the
+	// same paths as the others.
+	if *crdFolder != "" {
+		crdm.Validate(crdm.LoadDir(*crdFolder, diags), crds, diags)
+	}
 
-		for _, cond := range crd.Conditions {
-			condNode := hrend.NewConditionNode(cond.Name, cond.Description)
+	if err := linkSources(crds, *sourceRoot, *sourceURL); err != nil {
+		failf("source links: %v", err)
+	}
+
+	// Report everything that was found before giving up on the run. Without errors, the diagnostics are
+	// reported at the end of the run along with the warnings of the injection.
+	reportDiagnostics := func() {
+		if err := writeDiagnostics(*diagFormat, *diagOut, diags.Diagnostics()); err != nil {
+			failf("write diagnostics: %v", err)
+		}
+	}
+	if diags.HasErrors() {
+		reportDiagnostics()
+		os.Exit(1)
+	}
+
+	// With -check, -diff or -dry-run nothing is written, see fileWriter. -check fails the run at the end
+	// if a file is out of date.
+	files := &fileWriter{mode: mode, stdout: os.Stdout}
+	defer files.finish()
+	defer reportDiagnostics()
 
-			for _, r := range cond.Reasons {
-				condNode.AddChild(hrend.NewReasonNode(r.Name, r.Description))
+	if *exportFormat != "" {
+		if err := writeExport(files, *exportFormat, *exportOut, crds); err != nil {
+			failf("export: %v", err)
+		}
+		return
+	}
+
+	// Markdown files are updated in place, the default outputs are only written as well if they were
+	// asked for explicitly.
+	if len(markdownTargets) > 0 {
+		if err := injectMarkdownFiles(files, markdownTargets, crds); err != nil {
+			failf("inject: %v", err)
+		}
+		if !explicit["inject-into"] && !explicit["out"] {
+			return
+		}
+	}
+
+	if *format == "markdown" {
+		mdOut, err := renderMarkdown(*title, crds)
+		if err != nil {
+			failf("render error: %v", err)
+		}
+		files.Fragment("", mdOut)
+		if err := files.Write(*out, mdOut); err != nil {
+			failf("write %s: %v", *out, err)
+		}
+		return
+	}
+
+	var merged string
+	if *perCRD {
+		if *injectPath == "" {
+			failf("-per-crd requires -inject-into")
+		}
+		base, ok, err := files.Read(*injectPath)
+		if err != nil {
+			failf("read %s: %v", *injectPath, err)
+		}
+		if !ok {
+			// without a page there are no cards, every CRD would end up in the section
+			htmlOut, err := renderHTML(*title, crds, false)
+			if err != nil {
+				failf("render error: %v", err)
+			}
+			files.Fragment("", htmlOut)
+			if err := files.Write(*injectPath, htmlOut); err != nil {
+				failf("write %s: %v", *injectPath, err)
 			}
-			crdNode.AddChild(condNode)
+			return
+		}
+		if merged, err = injectPerCRD(files, diags, base, *injectPath, *title, crds, crdTargets, target); err != nil {
+			failf("inject: %v", err)
+		}
+	} else {
+		htmlOut, err := renderHTML(*title, crds, *standalone)
+		if err != nil {
+			failf("render error: %v", err)
+		}
+
+		files.Fragment("", htmlOut)
+
+		if *standalone {
+			if err := files.Write(*out, htmlOut); err != nil {
+				failf("write %s: %v", *out, err)
+			}
+			return
+		}
+		if *injectPath == "" {
+			return
+		}
+
+		base, ok, err := files.Read(*injectPath)
+		if err != nil {
+			failf("read %s: %v", *injectPath, err)
+		}
+		if !ok {
+			if err := files.Write(*injectPath, htmlOut); err != nil {
+				failf("write %s: %v", *injectPath, err)
+			}
+			return
+		}
+		if merged, err = hr.Inject(base, htmlOut, target); err != nil {
+			failf("inject: %v", err)
+		}
+	}
+
+	// without -allowed-types, the lists of an earlier run are kept, but no longer updated
+	if *allowedTypes {
+		merged, err = injectAllowedTypes(files, diags, merged, *injectPath, crds, crdTargets, propTargets)
+		if err != nil {
+			failf("inject: %v", err)
+		}
+	} else if listed, err := hr.InjectedIntoProperties(merged); err != nil {
+		failf("inject: %v", err)
+	} else if listed {
+		diags.Warnf(
+			diagnostics.Position{File: *injectPath},
+			"the allowed types listed by an earlier run are not updated without -allowed-types",
+		)
+	}
+
+	if err := files.Write(*injectPath, merged); err != nil {
+		failf("write %s: %v", *injectPath, err)
+	}
+}
+
+// -------- Rendering --------
+
+// renderHTML builds the component tree of the CTY styled HTML section and renders it, wrapped in a
+// self-contained page if standalone is set.
+func renderHTML(title string, crds []model.CRD, standalone bool) (string, error) {
+	section := hrend.NewSectionNode(title)
+	for _, crd := range crds {
+		crdNode := hrend.NewCRDNode(crd)
+
+		for _, cond := range crd.Conditions {
+			crdNode.AddChild(htmlConditionNode(crd, cond))
 		}
 
 		section.AddChild(crdNode)
 	}
 
-	htmlOut, err := section.Generate()
-	if err != nil {
-		failf("render error: %v", err)
+	var root hr.Generator = section
+	if standalone {
+		root = hrend.NewPageNode(title)
+		root.AddChild(section)
+	}
+	htmlOut, err := root.Generate()
+	return string(htmlOut), err
+}
+
+// renderCRDSection renders the conditions of a single CRD, for its block in the CTY output.
+func renderCRDSection(crd model.CRD) (string, error) {
+	node := hrend.NewCRDSectionNode(crd)
+	for _, cond := range crd.Conditions {
+		node.AddChild(htmlConditionNode(crd, cond))
 	}
+	htmlOut, err := node.Generate()
+	return string(htmlOut), err
+}
+
+// htmlConditionNode builds the node of a condition of crd with its reasons.
+func htmlConditionNode(crd model.CRD, cond model.ConditionDoc) *hrend.ConditionNode {
+	node := hrend.NewConditionNode(crd, cond)
+	for _, r := range cond.Reasons {
+		node.AddChild(hrend.NewReasonNode(r))
+	}
+	return node
+}
 
-	if *injectPath != "" {
-		baseBytes, err := os.ReadFile(*injectPath)
+// injectPerCRD injects the conditions of every CRD into its block in the CTY page base, read from
+// path. The CRDs whose block is not found are injected together as one section at fallback, with a
+// warning reported to diags.
+func injectPerCRD(
+	files *fileWriter, diags *diagnostics.Collector,
+	base, path, title string, crds []model.CRD, targets hr.CRDTargets, fallback hr.Target,
+) (string, error) {
+	fragments := make([]hr.CRDFragment, len(crds))
+	byFragment := map[string]model.CRD{}
+	for i, crd := range crds {
+		htmlOut, err := renderCRDSection(crd)
 		if err != nil {
-			failf("read %s: %v", *injectPath, err)
+			return "", fmt.Errorf("render %s: %w", crd.Name, err)
 		}
+		fragments[i] = crdFragment(crd, htmlOut)
+		byFragment[fragments[i].String()] = crd
+		files.Fragment(fragments[i].String(), htmlOut)
+	}
 
-		merged, err := injectIntoContentString(string(baseBytes), string(htmlOut))
+	assets, err := hrend.NewCRDSectionAssetsNode().Generate()
+	if err != nil {
+		return "", err
+	}
+	merged, unplaced, err := hr.InjectPerCRD(base, fragments, string(assets), targets)
+	if err != nil {
+		return "", err
+	}
+	if len(unplaced) > 0 {
+		rest := make([]model.CRD, len(unplaced))
+		for i, f := range unplaced {
+			diags.Warnf(
+				diagnostics.Position{File: path}, "no %s names %s, injecting it at %s", targets.Block, f, fallback.Selector,
+			)
+			rest[i] = byFragment[f.String()]
+		}
+		htmlOut, err := renderHTML(title, rest, false)
 		if err != nil {
-			failf("inject: %v")
+			return "", err
+		}
+		fallback.KeepPerCRD = true
+		if merged, err = hr.Inject(merged, htmlOut, fallback); err != nil {
+			return "", err
 		}
+	}
+	return merged, nil
+}
 
-		if err := os.WriteFile(*injectPath, []byte(merged), 0o644); err != nil {
-			failf("write %s: %v", *injectPath, err)
+// injectAllowedTypes lists the documented conditions of every CRD at the conditions property of its
+// schema in the CTY page base, read from path, and removes the lists of CRDs not given. A condition only
+// links to its entry if base has it. CRDs whose property is not found are skipped with a warning reported
+// to diags.
+func injectAllowedTypes(
+	files *fileWriter, diags *diagnostics.Collector,
+	base, path string, crds []model.CRD, blocks hr.CRDTargets, props hr.PropertyTargets,
+) (string, error) {
+	ids := hr.IDs(base)
+	linked := func(anchor string) bool { return ids[anchor] }
+	fragments := make([]hr.CRDFragment, len(crds))
+	for i, crd := range crds {
+		htmlOut, err := hrend.NewAllowedTypesNode(crd, linked).Generate()
+		if err != nil {
+			return "", fmt.Errorf("render %s: %w", crd.Name, err)
 		}
-		return
+		fragments[i] = crdFragment(crd, string(htmlOut))
+		files.Fragment(fragments[i].String()+" "+strings.Join(props.Path, "."), string(htmlOut))
+	}
+	assets, err := hrend.NewAllowedTypesAssetsNode().Generate()
+	if err != nil {
+		return "", err
 	}
+	merged, unplaced, err := hr.InjectIntoProperty(base, fragments, string(assets), blocks, props)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range unplaced {
+		diags.Warnf(
+			diagnostics.Position{File: path}, "%s has no %s property, not listing its conditions",
+			f, strings.Join(props.Path, "."),
+		)
+	}
+	return merged, nil
 }
 
-// -------- Aggregation --------
+func crdFragment(crd model.CRD, htmlOut string) hr.CRDFragment {
+	return hr.CRDFragment{Kind: crd.Name, Group: crd.Group, Version: crd.Version, HTML: htmlOut}
+}
 
-func buildCRDConditionsFromResults(results []*tp.DocTagResult) []CRD {
-	// crd -> condName -> *ConditionDoc
-	crdMap := map[string]map[string]*ConditionDoc{}
+// renderMarkdown builds the component tree of the Markdown section and renders it.
+func renderMarkdown(title string, crds []model.CRD) (string, error) {
+	section := mdrend.NewSectionNode(title)
+	for _, crd := range crds {
+		section.AddChild(markdownCRDNode(crd))
+	}
+	return section.Generate()
+}
 
-	getCRDSet := func(name string) map[string]*ConditionDoc {
-		if crdMap[name] == nil {
-			crdMap[name] = map[string]*ConditionDoc{}
+// renderMarkdownCRDs renders the CRDs without the section around them, for a region of a hand-written
+// Markdown file.
+func renderMarkdownCRDs(crds []model.CRD) (string, error) {
+	if len(crds) == 0 {
+		return "_No conditions found._\n", nil
+	}
+	var parts []string
+	for _, crd := range crds {
+		part, err := markdownCRDNode(crd).Generate()
+		if err != nil {
+			return "", err
 		}
-		return crdMap[name]
+		parts = append(parts, part)
 	}
+	return strings.Join(parts, "\n"), nil
+}
 
-	for _, r := range results {
-		switch r.Type {
-		case tps.DocTagCondition:
-			crdName := r.TagValues["crd"]
-			condName := r.Variable["value"]
-			constID := r.Variable["const"]
-			if crdName == "" || condName == "" {
-				continue
+func markdownCRDNode(crd model.CRD) *mdrend.CRDNode {
+	crdNode := mdrend.NewCRDNode(crd)
+	for _, cond := range crd.Conditions {
+		condNode := mdrend.NewConditionNode(crd, cond)
+
+		for _, r := range cond.Reasons {
+			condNode.AddChild(mdrend.NewReasonNode(crd, cond.Name, r))
+		}
+		crdNode.AddChild(condNode)
+	}
+	return crdNode
+}
+
+// injectMarkdownFiles replaces the regions between cty-conditions markers in the Markdown files with
+// the CRDs their begin markers select. Directories are searched for .md files, those without markers
+// are left alone; a file named explicitly must have at least one region.
+func injectMarkdownFiles(files *fileWriter, targets []string, crds []model.CRD) error {
+	for _, target := range targets {
+		info, err := os.Stat(target)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if err := injectMarkdownFile(files, target, crds, true); err != nil {
+				return err
 			}
-			crdSet := getCRDSet(crdName)
-			if crdSet[condName] == nil {
-				crdSet[condName] = &ConditionDoc{
-					Name:        condName,
-					ConstName:   constID,
-					Description: strings.TrimSpace(r.Comment),
-				}
-			} else if crdSet[condName].Description == "" && strings.TrimSpace(r.Comment) != "" {
-				crdSet[condName].Description = strings.TrimSpace(r.Comment)
+			continue
+		}
+		err = filepath.WalkDir(target, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
 			}
-
-		case tps.DocTagReason:
-			crdName := r.TagValues["crd"]
-			condName := r.TagValues["condition"]
-			reasonName := r.Variable["value"]
-			constID := r.Variable["const"]
-			if crdName == "" || condName == "" || reasonName == "" {
-				continue
+			if d.IsDir() {
+				if p != target && skipDir(p) {
+					return filepath.SkipDir
+				}
+				return nil
 			}
-			crdSet := getCRDSet(crdName)
-			if crdSet[condName] == nil {
-				// placeholder condition if declared later/elsewhere
-				crdSet[condName] = &ConditionDoc{Name: condName}
+			if !strings.EqualFold(filepath.Ext(p), ".md") {
+				return nil
 			}
-			addReasonUnique(&crdSet[condName].Reasons, ReasonDoc{
-				Name:        reasonName,
-				ConstName:   constID,
-				Description: strings.TrimSpace(r.Comment),
-			})
+			return injectMarkdownFile(files, p, crds, false)
+		})
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// materialize & sort
-	var crds []CRD
-	for crdName, set := range crdMap {
-		var conds []ConditionDoc
-		for _, c := range set {
-			sort.Slice(c.Reasons, func(i, j int) bool { return c.Reasons[i].Name < c.Reasons[j].Name })
-			conds = append(conds, *c)
+func injectMarkdownFile(files *fileWriter, filename string, crds []model.CRD, requireMarkers bool) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	merged, regions, err := md.InjectBetweenMarkers(string(data), func(m md.Marker) (string, error) {
+		selected, err := selectCRDs(crds, m.CRDs)
+		if err != nil {
+			return "", err
 		}
-		sort.Slice(conds, func(i, j int) bool { return conds[i].Name < conds[j].Name })
-		crds = append(crds, CRD{Name: crdName, Conditions: conds})
+		content, err := renderMarkdownCRDs(selected)
+		if err != nil {
+			return "", err
+		}
+		files.Fragment(fmt.Sprintf("%s:%d", filename, m.Line), content)
+		return content, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
 	}
-	sort.Slice(crds, func(i, j int) bool { return crds[i].Name < crds[j].Name })
-	return crds
+	if regions == 0 {
+		if requireMarkers {
+			return fmt.Errorf("%s: no <!-- cty-conditions:begin --> markers found", filename)
+		}
+		return nil
+	}
+	if merged == string(data) {
+		return nil
+	}
+	return files.Write(filename, merged)
 }
 
-func addReasonUnique(slice *[]ReasonDoc, r ReasonDoc) {
-	for _, ex := range *slice {
-		if ex.Name == r.Name || ex.ConstName == r.ConstName {
-			return
+// selectCRDs returns the CRDs matching any of the references, all of them if there are none. A
+// reference that matches no documented CRD is an error, as it is most likely a typo.
+func selectCRDs(crds []model.CRD, refs []string) ([]model.CRD, error) {
+	if len(refs) == 0 {
+		return crds, nil
+	}
+	parsed := make([]tps.CRDRef, len(refs))
+	for i, s := range refs {
+		ref, err := tps.ParseCRDRef(s)
+		if err != nil {
+			return nil, err
 		}
+		parsed[i] = ref
 	}
-	*slice = append(*slice, r)
+	var selected []model.CRD
+	matched := make([]bool, len(refs))
+	for _, crd := range crds {
+		hit := false
+		for i, ref := range parsed {
+			if ref.Matches(tps.CRDRef{Group: crd.Group, Version: crd.Version, Kind: crd.Name}) {
+				matched[i], hit = true, true
+			}
+		}
+		if hit {
+			selected = append(selected, crd)
+		}
+	}
+	for i, ok := range matched {
+		if !ok {
+			return nil, fmt.Errorf("crd=%s matches no documented CRD", refs[i])
+		}
+	}
+	return selected, nil
 }
 
-func failf(f string, a ...any) {
-	_, _ = fmt.Fprintf(os.Stderr, f+"\n", a...)
-	os.Exit(1)
+// linkSources makes the files the conditions and reasons were documented in relative to root and, if
+// urlTemplate is set, links the declarations by replacing {file} and {line} in it.
+func linkSources(crds []model.CRD, root, urlTemplate string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	relative := func(file *string) error {
+		if *file == "" {
+			return nil
+		}
+		abs, err := filepath.Abs(*file)
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(absRoot, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			*file = filepath.ToSlash(rel)
+		}
+		return nil
+	}
+	link := func(d *model.GoDecl, p *model.Provenance) error {
+		if err := relative(&p.File); err != nil {
+			return err
+		}
+		if d.File == "" {
+			return nil
+		}
+		if err := relative(&d.File); err != nil {
+			return err
+		}
+		if urlTemplate != "" {
+			d.URL = strings.NewReplacer("{file}", d.File, "{line}", strconv.Itoa(d.Line)).Replace(urlTemplate)
+		}
+		return nil
+	}
+	for i := range crds {
+		for j := range crds[i].Conditions {
+			cond := &crds[i].Conditions[j]
+			if err := link(&cond.Decl, &cond.Provenance); err != nil {
+				return err
+			}
+			for k := range cond.Reasons {
+				if err := link(&cond.Reasons[k].Decl, &cond.Reasons[k].Provenance); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
 }
 
-// injectIntoContentString appends fragment HTML to the last <div class="content"> in base.
-// It returns the full updated HTML as a string.
-func injectIntoContentString(base, fragment string) (string, error) {
-	doc, err := html.Parse(strings.NewReader(base))
-	if err != nil {
-		return "", err
+// skipDir returns true for directories that hold dependencies, build output or test data rather than
+// the sources to document.
+func skipDir(p string) bool {
+	switch base := filepath.Base(p); {
+	case strings.HasPrefix(base, "."), base == "vendor", base == "testdata":
+		return true
+	case base == "node_modules", base == "__pycache__":
+		return true
+	case base == "target":
+		// cargo build output, next to the Cargo.toml of the crate
+		_, err := os.Stat(filepath.Join(filepath.Dir(p), "Cargo.toml"))
+		return err == nil
+	}
+	return false
+}
+
+// writeExport writes the export document to the file out, or to stdout if out is empty.
+func writeExport(files *fileWriter, format, out string, crds []model.CRD) error {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, crds); err != nil {
+		return err
 	}
+	files.Fragment("", buf.String())
+	return files.Write(out, buf.String())
+}
+
+type writeMode int
+
+const (
+	writeFiles     writeMode = iota
+	checkFiles               // -check
+	diffFiles                // -diff
+	printFragments           // -dry-run
+)
+
+// fileWriter writes the generated files, or instead of writing them:
+//   - checkFiles prints the difference between each file and what would have been written, and remembers
+//     the files that are out of date,
+//   - diffFiles prints the same difference, HTML files being normalized first so that only changes to
+//     the document show up,
+//   - printFragments prints the rendered fragments passed to Fragment.
+type fileWriter struct {
+	mode   writeMode
+	stdout io.Writer
+	stale  []string
+}
+
+// Fragment is called with every rendered fragment before it is written or injected. target names the
+// place it goes to if there are several, such as the regions of Markdown files.
+func (w *fileWriter) Fragment(target, s string) {
+	if w.mode != printFragments {
+		return
+	}
+	if target != "" {
+		_, _ = fmt.Fprintf(w.stdout, "==> %s <==\n", target)
+	}
+	_, _ = io.WriteString(w.stdout, s)
+	if !strings.HasSuffix(s, "\n") {
+		_, _ = io.WriteString(w.stdout, "\n")
+	}
+}
 
-	// Find all <div class="content">; append to the last one.
-	var contents []*html.Node
-	var walk func(*html.Node)
-	walk = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "div" && hasClass(n, "content") {
-			contents = append(contents, n)
+// Read returns the contents of the file path a fragment is injected into. A missing file is only an
+// error when files are written, otherwise ok is false and the fragment is passed to Write as is: -check
+// and -diff compare it with an empty document, -dry-run has printed it already.
+func (w *fileWriter) Read(path string) (s string, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && w.mode != writeFiles {
+		return "", false, nil
+	}
+	return string(data), err == nil, err
+}
+
+// Write writes s to the file out, or to stdout if out is empty.
+func (w *fileWriter) Write(out, s string) error {
+	switch {
+	case w.mode == printFragments:
+		return nil
+	case out == "" && w.mode != writeFiles:
+		return errors.New("-check and -diff compare the generated files, the output must be written to a file")
+	case out == "":
+		_, err := io.WriteString(w.stdout, s)
+		return err
+	case w.mode == writeFiles:
+		return os.WriteFile(out, []byte(s), 0o644)
+	}
+
+	// a missing file is compared as an empty one
+	data, err := os.ReadFile(out)
+	missing := errors.Is(err, fs.ErrNotExist)
+	if err != nil && !missing {
+		return err
+	}
+	current := string(data)
+	if current == s {
+		return nil
+	}
+	if w.mode == checkFiles {
+		w.stale = append(w.stale, out)
+	} else if ext := strings.ToLower(filepath.Ext(out)); !missing && (ext == ".html" || ext == ".htm") {
+		if current, err = normalizeHTML(current); err != nil {
+			return err
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			walk(c)
+		if s, err = normalizeHTML(s); err != nil {
+			return err
 		}
 	}
-	walk(doc)
-	if len(contents) == 0 {
-		return "", errors.New(`no <div class="content"> found`)
+	_, err = io.WriteString(w.stdout, textdiff.Unified(out, out+" (generated)", current, s, 3))
+	return err
+}
+
+// finish fails the run if a file checked by Write is out of date.
+func (w *fileWriter) finish() {
+	if len(w.stale) > 0 {
+		failf("%d file(s) out of date, regenerate them: %s", len(w.stale), strings.Join(w.stale, ", "))
 	}
-	target := contents[len(contents)-1]
+}
 
-	// Parse the fragment in the context of the target and append
-	nodes, err := html.ParseFragment(strings.NewReader(fragment), target)
+// normalizeHTML parses and renders s again, so that documents that only differ in how the same DOM is
+// serialized compare equal.
+func normalizeHTML(s string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(s))
 	if err != nil {
 		return "", err
 	}
-	for _, n := range nodes {
-		target.AppendChild(n)
-	}
-
 	var out bytes.Buffer
 	if err := html.Render(&out, doc); err != nil {
 		return "", err
@@ -250,16 +847,35 @@ func injectIntoContentString(base, fragment string) (string, error) {
 	return out.String(), nil
 }
 
-func hasClass(n *html.Node, class string) bool {
-	for _, a := range n.Attr {
-		if a.Key != "class" {
-			continue
-		}
-		for _, c := range strings.Fields(a.Val) {
-			if c == class {
-				return true
-			}
-		}
+// writeDiagnostics writes the diagnostics to the file out, or to stderr if out is empty.
+func writeDiagnostics(format, out string, diags []diagnostics.Diagnostic) error {
+	if out == "" {
+		return diagnostics.Write(os.Stderr, format, diags)
 	}
-	return false
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := diagnostics.Write(f, format, diags); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// stringsFlag collects the values of a flag that can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func failf(f string, a ...any) {
+	_, _ = fmt.Fprintf(os.Stderr, f+"\n", a...)
+	os.Exit(1)
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+func TestFileWriterMissingTarget(t *testing.T) {
+	const section = "<div>conditions</div>\n"
+	tests := []struct {
+		name      string
+		mode      writeMode
+		wantOut   string
+		wantStale bool
+		wantErr   bool
+	}{
+		{
+			name:    "dry-run prints the fragment only",
+			mode:    printFragments,
+			wantOut: section,
+		},
+		{
+			name:    "diff compares with an empty document",
+			mode:    diffFiles,
+			wantOut: "--- {path}\n+++ {path} (generated)\n@@ -0,0 +1 @@\n+<div>conditions</div>\n",
+		},
+		{
+			name:      "check counts a missing file as out of date",
+			mode:      checkFiles,
+			wantOut:   "--- {path}\n+++ {path} (generated)\n@@ -0,0 +1 @@\n+<div>conditions</div>\n",
+			wantStale: true,
+		},
+		{
+			name:    "writing fails",
+			mode:    writeFiles,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "index.html")
+			var out strings.Builder
+			w := &fileWriter{mode: tt.mode, stdout: &out}
+
+			base, ok, err := w.Read(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("reading a missing file did not fail")
+				}
+				return
+			}
+			if err != nil || ok || base != "" {
+				t.Fatalf("Read = %q, %v, %v, want an empty document", base, ok, err)
+			}
+			// what main does with a missing target
+			w.Fragment("", section)
+			if err := w.Write(path, section); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := strings.ReplaceAll(tt.wantOut, "{path}", path); out.String() != want {
+				t.Errorf("printed\n%s\nwant\n%s", out.String(), want)
+			}
+			if stale := len(w.stale) > 0; stale != tt.wantStale {
+				t.Errorf("stale = %v, want %v", w.stale, tt.wantStale)
+			}
+		})
+	}
+}
+
+func mustSelector(t *testing.T, s string) *hr.Selector {
+	t.Helper()
+	sel, err := hr.ParseSelector(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sel
+}
+
+// The CRDs the injection cannot place are reported as warnings, so that they are part of the json and
+// sarif diagnostics too.
+func TestInjectionWarnings(t *testing.T) {
+	const base = `<div class="content"><div class="card"><div class="card-header">Cluster</div></div></div>`
+	crds := []model.CRD{
+		{Name: "Cluster", Conditions: []model.ConditionDoc{{Name: "Ready", Type: "string"}}},
+		{Name: "Gateway", Group: "example.com", Version: "v1"},
+	}
+	blocks := hr.CRDTargets{
+		Block: mustSelector(t, ".card"), Header: mustSelector(t, ".card-header"), Placement: hr.Append,
+	}
+	fallback := hr.Target{Selector: mustSelector(t, "div.content"), Placement: hr.Append}
+	props := hr.PropertyTargets{
+		Property: mustSelector(t, ".accordion-item"), Name: mustSelector(t, ".property-name"),
+		Path: []string{"status", "conditions"},
+	}
+	files := &fileWriter{mode: writeFiles, stdout: &strings.Builder{}}
+	diags := diagnostics.NewCollector()
+
+	merged, err := injectPerCRD(files, diags, base, "index.html", "Conditions", crds, blocks, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := injectAllowedTypes(files, diags, merged, "index.html", crds, blocks, props); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"index.html: warning: no .card names example.com/v1/Gateway, injecting it at div.content",
+		"index.html: warning: Cluster has no status.conditions property, not listing its conditions",
+		"index.html: warning: example.com/v1/Gateway has no status.conditions property, not listing its conditions",
+	}
+	var got []string
+	for _, d := range diags.Diagnostics() {
+		got = append(got, d.Position().String()+": "+string(d.Severity)+": "+d.Message)
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnostics\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
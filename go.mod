@@ -3,3 +3,5 @@ module github.com/sourcehawk/cty-generator-addons
 go 1.25
 
 require golang.org/x/net v0.44.0
+
+require gopkg.in/yaml.v3 v3.0.1
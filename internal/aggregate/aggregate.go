@@ -0,0 +1,259 @@
+package aggregate
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+)
+
+// Build aggregates the tag results of the sources and catalogs into the CRD -> Condition -> Reason model,
+// sorted by name. Tags may target several CRDs and conditions, by listing them or with Wildcard; a
+// reference without group and version matches every documented CRD of its Kind. A condition documented
+// both in the sources and in a catalog keeps what the sources say, so the catalogs come last.
+func Build(results []*tp.DocTagResult, diags *diagnostics.Collector) []model.CRD {
+	// crd -> condName -> *ConditionDoc
+	crdMap := map[tps.CRDRef]map[string]*model.ConditionDoc{}
+
+	getCRDSet := func(crd tps.CRDRef) map[string]*model.ConditionDoc {
+		if crdMap[crd] == nil {
+			crdMap[crd] = map[string]*model.ConditionDoc{}
+		}
+		return crdMap[crd]
+	}
+
+	// matching returns the documented CRDs a (possibly partial or wildcard) reference points to
+	matching := func(ref tps.CRDRef) []tps.CRDRef {
+		var crds []tps.CRDRef
+		for crd := range crdMap {
+			if ref.Matches(crd) {
+				crds = append(crds, crd)
+			}
+		}
+		return crds
+	}
+
+	addCondition := func(crd tps.CRDRef, r *tp.DocTagResult, tag tps.ConditionTag) {
+		crdSet := getCRDSet(crd)
+		condName := r.Variable["value"]
+		if crdSet[condName] == nil {
+			crdSet[condName] = &model.ConditionDoc{Name: condName}
+		}
+		cond := crdSet[condName]
+		if r.FromCatalog && cond.Provenance.Origin == model.OriginSource {
+			diags.Warnf(
+				sourcePosition(r), "condition %s of %s is also documented in the sources, which take precedence",
+				condName, crd,
+			)
+		}
+		if cond.Provenance.Origin == "" {
+			cond.ConstName = r.Variable["const"]
+			cond.Decl = goDecl(r)
+			cond.Provenance = provenance(r)
+			cond.Type = r.Variable["type"]
+			cond.DisplayName = tag.DisplayName
+			cond.Polarity = string(tag.Polarity)
+			cond.Severity = string(tag.Severity)
+		}
+		if cond.Description == "" && strings.TrimSpace(r.Comment) != "" {
+			cond.Description = strings.TrimSpace(r.Comment)
+		}
+	}
+
+	// Qualify every reference with the group and version of the package it was declared in. A Kind
+	// declared in several API versions is then documented once per version.
+	type conditionRef struct {
+		r   *tp.DocTagResult
+		crd tps.CRDRef
+	}
+	type reasonRef struct {
+		r      *tp.DocTagResult
+		target tps.ReasonTarget
+	}
+	var condRefs []conditionRef
+	var reasonRefs []reasonRef
+	for _, r := range results {
+		if r.Variable["value"] == "" {
+			continue
+		}
+		switch tag := r.Tag.(type) {
+		case tps.ConditionTag:
+			for _, crd := range tag.CRDs {
+				condRefs = append(condRefs, conditionRef{r, crd.Qualified(r.Group, r.Version)})
+			}
+		case tps.ReasonTag:
+			for _, t := range tag.Targets {
+				t.CRD = t.CRD.Qualified(r.Group, r.Version)
+				reasonRefs = append(reasonRefs, reasonRef{r, t})
+			}
+		}
+	}
+
+	// Register the documented CRDs: fully qualified references first, then Kinds without a group or
+	// version that do not match any of those.
+	var crdRefs []tps.CRDRef
+	for _, c := range condRefs {
+		crdRefs = append(crdRefs, c.crd)
+	}
+	for _, rr := range reasonRefs {
+		crdRefs = append(crdRefs, rr.target.CRD)
+	}
+	for _, crd := range crdRefs {
+		if !crd.IsPattern() {
+			getCRDSet(crd)
+		}
+	}
+	for _, crd := range crdRefs {
+		if crd.Kind != tps.Wildcard && len(matching(crd)) == 0 {
+			getCRDSet(crd)
+		}
+	}
+
+	// Wildcards expand to the CRDs and conditions documented explicitly, so those are registered first:
+	// explicit conditions, then the conditions named by reasons, then wildcard conditions.
+	for _, c := range condRefs {
+		if c.crd.Kind == tps.Wildcard {
+			continue
+		}
+		for _, crd := range matching(c.crd) {
+			addCondition(crd, c.r, c.r.Tag.(tps.ConditionTag))
+		}
+	}
+	for _, rr := range reasonRefs {
+		if rr.target.CRD.Kind == tps.Wildcard || rr.target.Condition == tps.Wildcard {
+			continue
+		}
+		crds := matching(rr.target.CRD)
+		if slices.ContainsFunc(crds, func(crd tps.CRDRef) bool { return crdMap[crd][rr.target.Condition] != nil }) {
+			continue
+		}
+		for _, crd := range crds {
+			// placeholder condition if declared later/elsewhere
+			getCRDSet(crd)[rr.target.Condition] = &model.ConditionDoc{Name: rr.target.Condition, Type: "string"}
+		}
+	}
+	for _, c := range condRefs {
+		if c.crd.Kind != tps.Wildcard {
+			continue
+		}
+		crds := matching(c.crd)
+		if len(crds) == 0 {
+			diags.Warnf(
+				sourcePosition(c.r), "condition %s targets %s, which matches no documented CRD",
+				c.r.Variable["const"], c.crd,
+			)
+		}
+		for _, crd := range crds {
+			addCondition(crd, c.r, c.r.Tag.(tps.ConditionTag))
+		}
+	}
+
+	// Attach the reasons. A reason is shared if its tag targets several conditions, the same condition
+	// in several versions of a CRD counts once, the same Kind in several groups does not.
+	type reasonTargets struct {
+		conds    []*model.ConditionDoc
+		pairs    map[[3]string]bool // group, Kind, condition
+		wildcard bool
+	}
+	targetsOf := map[*tp.DocTagResult]*reasonTargets{}
+	var order []*tp.DocTagResult
+	for _, rr := range reasonRefs {
+		rt := targetsOf[rr.r]
+		if rt == nil {
+			rt = &reasonTargets{pairs: map[[3]string]bool{}}
+			targetsOf[rr.r] = rt
+			order = append(order, rr.r)
+		}
+		t := rr.target
+		rt.wildcard = rt.wildcard || t.CRD.Kind == tps.Wildcard || t.Condition == tps.Wildcard
+		for _, crd := range matching(t.CRD) {
+			for condName, cond := range crdMap[crd] {
+				if t.Condition == tps.Wildcard || t.Condition == condName {
+					rt.conds = append(rt.conds, cond)
+					rt.pairs[[3]string{crd.Group, crd.Kind, condName}] = true
+				}
+			}
+		}
+	}
+	for _, r := range order {
+		tag := r.Tag.(tps.ReasonTag)
+		rt := targetsOf[r]
+		if len(rt.conds) == 0 {
+			diags.Warnf(sourcePosition(r), "reason %s matches no documented condition", r.Variable["const"])
+		}
+		for _, cond := range rt.conds {
+			addReasonUnique(&cond.Reasons, model.ReasonDoc{
+				Name:        r.Variable["value"],
+				ConstName:   r.Variable["const"],
+				DisplayName: tag.DisplayName,
+				Severity:    string(tag.Severity),
+				Shared:      rt.wildcard || len(rt.pairs) > 1,
+				Description: strings.TrimSpace(r.Comment),
+				Decl:        goDecl(r),
+				Provenance:  provenance(r),
+			})
+		}
+	}
+
+	// materialize & sort
+	var crds []model.CRD
+	for crd, set := range crdMap {
+		var conds []model.ConditionDoc
+		for _, c := range set {
+			sort.Slice(c.Reasons, func(i, j int) bool { return c.Reasons[i].Name < c.Reasons[j].Name })
+			conds = append(conds, *c)
+		}
+		sort.Slice(conds, func(i, j int) bool { return conds[i].Name < conds[j].Name })
+		crds = append(crds, model.CRD{Name: crd.Kind, Group: crd.Group, Version: crd.Version, Conditions: conds})
+	}
+	sort.Slice(crds, func(i, j int) bool {
+		if crds[i].Name != crds[j].Name {
+			return crds[i].Name < crds[j].Name
+		}
+		if crds[i].Group != crds[j].Group {
+			return crds[i].Group < crds[j].Group
+		}
+		return crds[i].Version < crds[j].Version
+	})
+	return crds
+}
+
+func goDecl(r *tp.DocTagResult) model.GoDecl {
+	if r.FromCatalog {
+		return model.GoDecl{}
+	}
+	return model.GoDecl{
+		ImportPath:  r.Source.ImportPath,
+		PackageName: r.Source.PackageName,
+		Ident:       r.Source.Ident,
+		Kind:        r.Source.Kind,
+		TypeName:    r.Source.TypeName,
+		File:        r.Source.File,
+		Line:        r.Source.Line,
+	}
+}
+
+func provenance(r *tp.DocTagResult) model.Provenance {
+	origin := model.OriginSource
+	if r.FromCatalog {
+		origin = model.OriginCatalog
+	}
+	return model.Provenance{Origin: origin, File: r.Source.File, Line: r.Source.Line}
+}
+
+func sourcePosition(r *tp.DocTagResult) diagnostics.Position {
+	return diagnostics.Position{File: r.Source.File, Line: r.Source.Line, Column: r.Source.Column}
+}
+
+func addReasonUnique(slice *[]model.ReasonDoc, r model.ReasonDoc) {
+	for _, ex := range *slice {
+		if ex.Name == r.Name || (r.ConstName != "" && ex.ConstName == r.ConstName) {
+			return
+		}
+	}
+	*slice = append(*slice, r)
+}
@@ -0,0 +1,288 @@
+package aggregate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+)
+
+// source returns the result of the constant name, whose value is its name, tagged with tagLine in a
+// package serving gv, the "<group>/<version>" or "" if it is unknown.
+func source(t *testing.T, gv, tagLine, name string) *tp.DocTagResult {
+	t.Helper()
+	var parser tp.DocTagParser = tps.ConditionTagParser{}
+	if strings.HasPrefix(tagLine, "+cty:reason:") {
+		parser = tps.ReasonTagParser{}
+	}
+	tag, err := parser.ParseTag(tagLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, version, _ := strings.Cut(gv, "/")
+	return &tp.DocTagResult{
+		Comment:  strings.TrimSpace(name + " documented in " + gv),
+		Variable: map[string]string{"const": name, "value": name, "type": "string"},
+		Tag:      tag,
+		Type:     parser.Type(),
+		Group:    group,
+		Version:  version,
+		Source:   tp.Source{File: "api.go", Line: 1},
+	}
+}
+
+// summary writes a CRD per line: "<Kind> <group>/<version>: <Condition>(<Reason>, ...) ...", shared
+// reasons end in '*'.
+func summary(crds []model.CRD) string {
+	var lines []string
+	for _, crd := range crds {
+		line := crd.Name
+		if gv := crd.GroupVersion(); gv != "" {
+			line += " " + gv
+		}
+		line += ":"
+		for _, cond := range crd.Conditions {
+			var reasons []string
+			for _, r := range cond.Reasons {
+				if r.Shared {
+					r.Name += "*"
+				}
+				reasons = append(reasons, r.Name)
+			}
+			line += fmt.Sprintf(" %s(%s)", cond.Name, strings.Join(reasons, ","))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type buildTest struct {
+	name      string
+	results   func(t *testing.T) []*tp.DocTagResult
+	want      []string
+	wantDiags []string // parts of the messages
+}
+
+func runBuildTests(t *testing.T, tests []buildTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := diagnostics.NewCollector()
+			got := summary(Build(tt.results(t), diags))
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+			gotDiags := diags.Diagnostics()
+			if len(gotDiags) != len(tt.wantDiags) {
+				t.Fatalf("diagnostics %v, want %v", gotDiags, tt.wantDiags)
+			}
+			for i, d := range gotDiags {
+				if !strings.Contains(d.Message, tt.wantDiags[i]) {
+					t.Errorf("diagnostic %q, want one containing %q", d.Message, tt.wantDiags[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildTargets(t *testing.T) {
+	runBuildTests(t, []buildTest{
+		{
+			name: "a list of CRDs",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "", "+cty:condition:for=Cluster,Gateway", "Ready"),
+					source(t, "", "+cty:reason:for=Cluster/Ready", "Starting"),
+					source(t, "", "+cty:reason:for=Cluster,Gateway/Ready", "Failed"),
+				}
+			},
+			want: []string{
+				"Cluster: Ready(Failed*,Starting)",
+				"Gateway: Ready(Failed*)",
+			},
+		},
+		{
+			name: "wildcards expand to the explicitly documented CRDs and conditions",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "", "+cty:condition:for=*", "Ready"),
+					source(t, "", "+cty:condition:for=Cluster", "Synced"),
+					source(t, "", "+cty:condition:for=Gateway", "Routed"),
+					source(t, "", "+cty:reason:for=*/Ready", "Starting"),
+					source(t, "", "+cty:reason:for=Cluster/*", "Failed"),
+					source(t, "", "+cty:reason:for=Gateway/Routed", "Unreachable"),
+				}
+			},
+			want: []string{
+				"Cluster: Ready(Failed*,Starting*) Synced(Failed*)",
+				"Gateway: Ready(Starting*) Routed(Unreachable)",
+			},
+		},
+		{
+			name: "a wildcard without explicit CRDs",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "", "+cty:condition:for=*", "Ready"),
+					source(t, "", "+cty:reason:for=*/*", "Failed"),
+				}
+			},
+			wantDiags: []string{
+				"condition Ready targets *, which matches no documented CRD",
+				"reason Failed matches no documented condition",
+			},
+		},
+		{
+			name: "a reason naming a condition that is not documented",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "", "+cty:condition:for=Cluster", "Ready"),
+					source(t, "", "+cty:reason:for=Cluster/Degraded", "Failed"),
+					source(t, "", "+cty:reason:for=Gateway/Ready", "Starting"),
+				}
+			},
+			want: []string{
+				"Cluster: Degraded(Failed) Ready()",
+				"Gateway: Ready(Starting)",
+			},
+		},
+		{
+			name: "duplicate names are documented once, the first declaration wins",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				ready := source(t, "", "+cty:condition:for=Cluster", "Ready")
+				again := source(t, "", "+cty:condition:for=Cluster,polarity=negative", "Ready")
+				again.Variable["const"] = "ReadyAgain"
+				failed := source(t, "", "+cty:reason:for=Cluster/Ready", "Failed")
+				failedAgain := source(t, "", "+cty:reason:for=Cluster/*", "Failed")
+				return []*tp.DocTagResult{ready, again, failed, failedAgain}
+			},
+			want: []string{"Cluster: Ready(Failed)"},
+		},
+	})
+}
+
+func TestBuildFirstDeclarationWins(t *testing.T) {
+	ready := source(t, "", "+cty:condition:for=Cluster,polarity=positive", "Ready")
+	ready.Comment = ""
+	again := source(t, "", "+cty:condition:for=Cluster,polarity=negative", "Ready")
+	again.Variable["const"] = "ReadyAgain"
+
+	crds := Build([]*tp.DocTagResult{ready, again}, diagnostics.NewCollector())
+	cond := crds[0].Conditions[0]
+	if cond.ConstName != "Ready" || cond.Polarity != "positive" {
+		t.Errorf("condition %+v, want the one of the first declaration", cond)
+	}
+	// the description is taken from the first declaration that has one
+	if want := strings.TrimSpace(again.Comment); cond.Description != want {
+		t.Errorf("description %q, want %q", cond.Description, want)
+	}
+}
+
+func TestBuildGroupVersions(t *testing.T) {
+	runBuildTests(t, []buildTest{
+		{
+			name: "references are qualified with the group and version of their package",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "zeebe.example.com/v1", "+cty:condition:for=Cluster", "Ready"),
+					source(t, "zeebe.example.com/v1beta1", "+cty:condition:for=Cluster", "Synced"),
+					source(t, "zeebe.example.com/v1beta1", "+cty:reason:for=Cluster/Synced", "Applied"),
+				}
+			},
+			want: []string{
+				"Cluster zeebe.example.com/v1: Ready()",
+				"Cluster zeebe.example.com/v1beta1: Synced(Applied)",
+			},
+		},
+		{
+			name: "an unqualified reference matches every version",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "zeebe.example.com/v1", "+cty:condition:for=Cluster", "Ready"),
+					source(t, "zeebe.example.com/v1beta1", "+cty:condition:for=Cluster", "Ready"),
+					// the same condition in several versions is not shared
+					source(t, "", "+cty:reason:for=Cluster/Ready", "Starting"),
+					source(t, "", "+cty:condition:for=Cluster", "Synced"),
+				}
+			},
+			want: []string{
+				"Cluster zeebe.example.com/v1: Ready(Starting) Synced()",
+				"Cluster zeebe.example.com/v1beta1: Ready(Starting) Synced()",
+			},
+		},
+		{
+			name: "the same Kind in several groups",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "zeebe.example.com/v1", "+cty:condition:for=Cluster", "Ready"),
+					source(t, "operate.example.com/v1", "+cty:condition:for=Cluster", "Ready"),
+					source(t, "zeebe.example.com/v1", "+cty:reason:for=Cluster/Ready", "Starting"),
+					source(t, "zeebe.example.com/v1", "+cty:reason:for=operate.example.com/v1/Cluster/Ready", "Waiting"),
+					source(t, "", "+cty:reason:for=Cluster/Ready", "Failed"),
+				}
+			},
+			want: []string{
+				"Cluster operate.example.com/v1: Ready(Failed*,Waiting)",
+				"Cluster zeebe.example.com/v1: Ready(Failed*,Starting)",
+			},
+		},
+		{
+			name: "a qualified reference to a CRD documented nowhere else",
+			results: func(t *testing.T) []*tp.DocTagResult {
+				return []*tp.DocTagResult{
+					source(t, "", "+cty:condition:for=Cluster", "Ready"),
+					source(t, "", "+cty:condition:for=zeebe.example.com/v1/Gateway", "Routed"),
+				}
+			},
+			want: []string{
+				"Cluster: Ready()",
+				"Gateway zeebe.example.com/v1: Routed()",
+			},
+		},
+	})
+}
+
+func TestBuildCatalogs(t *testing.T) {
+	catalog := func(t *testing.T, tagLine, name string) *tp.DocTagResult {
+		r := source(t, "", tagLine, name)
+		r.Variable["const"] = ""
+		r.Comment = name + " documented in the catalog"
+		r.FromCatalog = true
+		r.Source = tp.Source{File: "catalog.yaml", Line: 7}
+		return r
+	}
+	results := []*tp.DocTagResult{
+		source(t, "zeebe.example.com/v1", "+cty:condition:for=Cluster,polarity=positive", "Ready"),
+		catalog(t, "+cty:condition:for=Cluster,polarity=negative", "Ready"),
+		catalog(t, "+cty:reason:for=Cluster/Ready", "Starting"),
+		catalog(t, "+cty:condition:for=Sidecar", "Injected"),
+	}
+	diags := diagnostics.NewCollector()
+	crds := Build(results, diags)
+
+	want := "Cluster zeebe.example.com/v1: Ready(Starting)\nSidecar: Injected()"
+	if got := summary(crds); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	ready := crds[0].Conditions[0]
+	if ready.Provenance.Origin != model.OriginSource || ready.Polarity != "positive" ||
+		ready.Description != "Ready documented in zeebe.example.com/v1" || ready.Decl.File != "api.go" {
+		t.Errorf("condition %+v, want the one of the sources", ready)
+	}
+	wantProvenance := model.Provenance{Origin: model.OriginCatalog, File: "catalog.yaml", Line: 7}
+	if r := ready.Reasons[0]; r.Provenance != wantProvenance || r.Decl != (model.GoDecl{}) {
+		t.Errorf("reason %+v, want one from catalog.yaml:7 without a Go declaration", r)
+	}
+	if p := crds[1].Conditions[0].Provenance; p.Origin != model.OriginCatalog {
+		t.Errorf("provenance %+v, want the catalog", p)
+	}
+
+	gotDiags := diags.Diagnostics()
+	if len(gotDiags) != 1 || gotDiags[0].File != "catalog.yaml" || gotDiags[0].Severity != diagnostics.SeverityWarning ||
+		!strings.Contains(gotDiags[0].Message, "also documented in the sources") {
+		t.Errorf("diagnostics %v, want a warning that the catalog entry is also documented in the sources", gotDiags)
+	}
+}
@@ -0,0 +1,251 @@
+package catalog
+
+// Catalog files document conditions that are not declared in any scanned source, such as those of
+// third-party controllers. They have the CRD -> Condition -> Reason shape of the rendered docs:
+//
+//	crds:
+//	  - kind: ZeebeCluster
+//	    group: zeebe.io      # optional
+//	    version: v1alpha1    # optional
+//	    conditions:
+//	      - name: SidecarReady
+//	        displayName: Sidecar ready
+//	        polarity: positive
+//	        severity: Error
+//	        description: Whether the Helm managed sidecar is ready.
+//	        reasons:
+//	          - name: SidecarStarting
+//	            severity: Info
+//	            description: The sidecar container is starting.
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+	"github.com/sourcehawk/cty-generator-addons/internal/yaml_docs"
+	"gopkg.in/yaml.v3"
+)
+
+type file struct {
+	CRDs []crd `yaml:"crds"`
+}
+
+type crd struct {
+	Kind       string      `yaml:"kind"`
+	Group      string      `yaml:"group"`
+	Version    string      `yaml:"version"`
+	Conditions []condition `yaml:"conditions"`
+	line       int
+	typeErrors []string
+}
+
+type condition struct {
+	Name        string   `yaml:"name"`
+	DisplayName string   `yaml:"displayName"`
+	Polarity    string   `yaml:"polarity"`
+	Severity    string   `yaml:"severity"`
+	Description string   `yaml:"description"`
+	Reasons     []reason `yaml:"reasons"`
+	line        int
+	typeErrors  []string
+}
+
+type reason struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"displayName"`
+	Severity    string `yaml:"severity"`
+	Description string `yaml:"description"`
+	line        int
+	typeErrors  []string
+}
+
+// The UnmarshalYAML methods record the line of each entry, the fields are checked by checkFields. They
+// keep the type errors of an entry with it, since yaml drops an entry whose UnmarshalYAML fails, so that
+// the values that could be decoded are still used.
+
+func (c *crd) UnmarshalYAML(n *yaml.Node) error {
+	type plain crd
+	c.line = n.Line
+	return keepTypeErrors(n.Decode((*plain)(c)), &c.typeErrors)
+}
+
+func (c *condition) UnmarshalYAML(n *yaml.Node) error {
+	type plain condition
+	c.line = n.Line
+	return keepTypeErrors(n.Decode((*plain)(c)), &c.typeErrors)
+}
+
+func (r *reason) UnmarshalYAML(n *yaml.Node) error {
+	type plain reason
+	r.line = n.Line
+	return keepTypeErrors(n.Decode((*plain)(r)), &r.typeErrors)
+}
+
+func keepTypeErrors(err error, typeErrors *[]string) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		*typeErrors = append(*typeErrors, typeErr.Errors...)
+		return nil
+	}
+	return err
+}
+
+// checkFields reports the fields of a catalog document that are not part of the format, so that typos
+// do not go unnoticed.
+func checkFields(doc *yaml.Node, report func(line int, msg string)) {
+	root := doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	for _, c := range checkKeys(root, report, "crds")["crds"] {
+		for _, cond := range checkKeys(c, report, "kind", "group", "version", "conditions")["conditions"] {
+			reasons := checkKeys(
+				cond, report, "name", "displayName", "polarity", "severity", "description", "reasons",
+			)["reasons"]
+			for _, r := range reasons {
+				checkKeys(r, report, "name", "displayName", "severity", "description")
+			}
+		}
+	}
+}
+
+// checkKeys reports the keys of a mapping other than the allowed ones, and returns the items of the
+// values that are sequences by key.
+func checkKeys(n *yaml.Node, report func(line int, msg string), allowed ...string) map[string][]*yaml.Node {
+	items := map[string][]*yaml.Node{}
+	if n.Kind != yaml.MappingNode {
+		return items
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if !slices.Contains(allowed, key.Value) {
+			report(key.Line, fmt.Sprintf("unknown field %q, expected one of: %s", key.Value, strings.Join(allowed, ", ")))
+			continue
+		}
+		if value.Kind == yaml.SequenceNode {
+			items[key.Value] = value.Content
+		}
+	}
+	return items
+}
+
+var reTypeErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// LoadFile reads a YAML or JSON catalog file and returns its entries as tag results, so that they are
+// aggregated like the tags found in the sources. Invalid entries are reported to diags and skipped.
+func LoadFile(filename string, diags *diagnostics.Collector) []*tp.DocTagResult {
+	report := func(line int, msg string) {
+		diags.Errorf(diagnostics.Position{File: filename, Line: line}, "%s", msg)
+	}
+
+	var results []*tp.DocTagResult
+	yaml_docs.DecodeFile(filename, diags, func(node *yaml.Node) {
+		checkFields(node, report)
+
+		var f file
+		if err := node.Decode(&f); err != nil {
+			var typeErr *yaml.TypeError
+			if !errors.As(err, &typeErr) {
+				report(node.Line, err.Error())
+				return
+			}
+			reportTypeErrors(typeErr.Errors, node.Line, report)
+		}
+		for _, c := range f.CRDs {
+			results = append(results, crdResults(filename, c, diags)...)
+		}
+	})
+	return results
+}
+
+// reportTypeErrors reports the type errors of an entry at their line, or at the line of the entry.
+func reportTypeErrors(typeErrors []string, line int, report func(line int, msg string)) {
+	for _, e := range typeErrors {
+		if m := reTypeErrorLine.FindStringSubmatch(e); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			report(line, m[2])
+		} else {
+			report(line, e)
+		}
+	}
+}
+
+func crdResults(filename string, c crd, diags *diagnostics.Collector) []*tp.DocTagResult {
+	report := func(line int, msg string) {
+		diags.Errorf(diagnostics.Position{File: filename, Line: line}, "%s", msg)
+	}
+	reportTypeErrors(c.typeErrors, c.line, report)
+	if c.Kind == "" {
+		diags.Errorf(diagnostics.Position{File: filename, Line: c.line}, "missing kind")
+		return nil
+	}
+	ref := tps.CRDRef{Group: c.Group, Version: c.Version, Kind: c.Kind}
+
+	var results []*tp.DocTagResult
+	for _, cond := range c.Conditions {
+		reportTypeErrors(cond.typeErrors, cond.line, report)
+		pos := diagnostics.Position{File: filename, Line: cond.line}
+		if cond.Name == "" {
+			diags.Errorf(pos, "missing name of a condition of %s", ref)
+			continue
+		}
+		polarity, err := tps.ParsePolarity(cond.Polarity)
+		if err != nil {
+			diags.Errorf(pos, "%v", err)
+			continue
+		}
+		severity, err := tps.ParseSeverity(cond.Severity)
+		if err != nil {
+			diags.Errorf(pos, "%v", err)
+			continue
+		}
+		results = append(results, result(filename, cond.line, cond.Name, cond.Description, tps.ConditionTag{
+			CRDs:        []tps.CRDRef{ref},
+			Polarity:    polarity,
+			Severity:    severity,
+			DisplayName: cond.DisplayName,
+		}))
+
+		for _, r := range cond.Reasons {
+			reportTypeErrors(r.typeErrors, r.line, report)
+			pos := diagnostics.Position{File: filename, Line: r.line}
+			if r.Name == "" {
+				diags.Errorf(pos, "missing name of a reason of condition %s", cond.Name)
+				continue
+			}
+			severity, err := tps.ParseSeverity(r.Severity)
+			if err != nil {
+				diags.Errorf(pos, "%v", err)
+				continue
+			}
+			results = append(results, result(filename, r.line, r.Name, r.Description, tps.ReasonTag{
+				Targets:     []tps.ReasonTarget{{CRD: ref, Condition: cond.Name}},
+				Severity:    severity,
+				DisplayName: r.DisplayName,
+			}))
+		}
+	}
+	return results
+}
+
+func result(filename string, line int, name, description string, tag tp.DocTag) *tp.DocTagResult {
+	return &tp.DocTagResult{
+		Comment: strings.TrimSpace(description),
+		Variable: map[string]string{
+			"const": "",
+			"value": name,
+			"type":  "string",
+		},
+		Tag:         tag,
+		Type:        tag.Type(),
+		Source:      tp.Source{File: filename, Line: line},
+		FromCatalog: true,
+	}
+}
@@ -0,0 +1,184 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// want holds a line per result: <line> <tag type> <name> <target> <severity> | <description>
+		want []string
+		// wantDiags holds a line per diagnostic: <line>: <part of its message>
+		wantDiags []string
+	}{
+		{
+			name: "conditions and reasons",
+			content: `crds:
+  - kind: Cluster
+    group: zeebe.example.com
+    version: v1
+    conditions:
+      - name: SidecarReady
+        polarity: positive
+        severity: Error
+        description: |
+          Whether the sidecar is ready.
+        reasons:
+          - name: SidecarStarting
+            severity: Info
+            description: The sidecar is starting.
+  - kind: Gateway
+    conditions:
+      - name: Routed
+`,
+			want: []string{
+				"6 condition SidecarReady zeebe.example.com/v1/Cluster Error | Whether the sidecar is ready.",
+				"12 reason SidecarStarting zeebe.example.com/v1/Cluster/SidecarReady Info | The sidecar is starting.",
+				"17 condition Routed Gateway  | ",
+			},
+		},
+		{
+			name:    "JSON",
+			content: `{"crds": [{"kind": "Cluster", "conditions": [{"name": "Ready"}]}]}`,
+			want:    []string{"1 condition Ready Cluster  | "},
+		},
+		{
+			name: "several documents",
+			content: `crds:
+  - kind: Cluster
+    conditions:
+      - name: Ready
+---
+crds:
+  - kind: Gateway
+    conditions:
+      - name: Routed
+`,
+			want: []string{
+				"4 condition Ready Cluster  | ",
+				"9 condition Routed Gateway  | ",
+			},
+		},
+		{
+			name: "unknown fields",
+			content: `crds:
+  - kind: Cluster
+    conditions:
+      - name: Ready
+        reason:
+          - name: Failed
+        reasons:
+          - name: Starting
+            severty: Info
+`,
+			want: []string{
+				"4 condition Ready Cluster  | ",
+				"8 reason Starting Cluster/Ready  | ",
+			},
+			wantDiags: []string{
+				`5: unknown field "reason", expected one of: name, displayName, polarity`,
+				`9: unknown field "severty", expected one of: name, displayName, severity, description`,
+			},
+		},
+		{
+			name: "invalid entries are skipped",
+			content: `crds:
+  - conditions:
+      - name: Orphan
+  - kind: Cluster
+    conditions:
+      - description: no name
+      - name: Ready
+        polarity: sideways
+      - name: Synced
+        reasons:
+          - severity: Info
+          - name: Failed
+            severity: Fatal
+          - name: Applied
+`,
+			want: []string{
+				"9 condition Synced Cluster  | ",
+				"14 reason Applied Cluster/Synced  | ",
+			},
+			wantDiags: []string{
+				"2: missing kind",
+				"6: missing name of a condition of Cluster",
+				`7: invalid polarity "sideways"`,
+				"11: missing name of a reason of condition Synced",
+				`12: invalid severity "Fatal"`,
+			},
+		},
+		{
+			name: "values of the wrong type",
+			content: `crds:
+  - kind: Cluster
+    conditions:
+      - name: Ready
+        reasons: none
+      - name: Synced
+`,
+			want: []string{
+				"4 condition Ready Cluster  | ",
+				"6 condition Synced Cluster  | ",
+			},
+			wantDiags: []string{"5: cannot unmarshal !!str `none` into []catalog.reason"},
+		},
+		{
+			name:      "syntax error",
+			content:   "crds:\n  - kind: Cluster\n    conditions: [\n",
+			wantDiags: []string{"3: did not find expected node content"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "catalog.yaml")
+			if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			diags := diagnostics.NewCollector()
+			results := LoadFile(filename, diags)
+
+			var got []string
+			for _, r := range results {
+				if !r.FromCatalog || r.Source.File != filename || r.Variable["const"] != "" {
+					t.Errorf("result %+v, want one from the catalog %s without a constant", r, filename)
+				}
+				var target, severity string
+				switch tag := r.Tag.(type) {
+				case tps.ConditionTag:
+					target, severity = tag.CRDs[0].String(), string(tag.Severity)
+				case tps.ReasonTag:
+					target = tag.Targets[0].CRD.String() + "/" + tag.Targets[0].Condition
+					severity = string(tag.Severity)
+				}
+				got = append(got, fmt.Sprintf(
+					"%d %s %s %s %s | %s", r.Source.Line, r.Type, r.Variable["value"], target, severity, r.Comment,
+				))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("results\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+
+			gotDiags := diags.Diagnostics()
+			if len(gotDiags) != len(tt.wantDiags) {
+				t.Fatalf("diagnostics %v, want %v", gotDiags, tt.wantDiags)
+			}
+			for i, d := range gotDiags {
+				line, msg, _ := strings.Cut(tt.wantDiags[i], ": ")
+				if fmt.Sprint(d.Line) != line || d.File != filename || !strings.Contains(d.Message, msg) {
+					t.Errorf("diagnostic %s:%d: %s, want line %s: %s", d.File, d.Line, d.Message, line, msg)
+				}
+			}
+		})
+	}
+}
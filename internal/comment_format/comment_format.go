@@ -0,0 +1,87 @@
+package comment_format
+
+// Splits the descriptions of conditions and reasons into paragraphs and bullet lists, so that every
+// renderer formats a comment the same way.
+
+import "strings"
+
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	BulletList
+)
+
+// Block is a paragraph or a bullet list of a comment. Text is set for paragraphs, Items for lists.
+type Block struct {
+	Kind  BlockKind
+	Text  string
+	Items []string
+}
+
+// Parse splits plain text (with lines starting `-`, `•`, or `*`) into blocks. Empty lines create
+// paragraph breaks. Consecutive non-bullet lines become a single paragraph, joined with a space so
+// that wrapped comments read as one.
+func Parse(s string) []Block {
+	if s == "" {
+		return nil
+	}
+
+	var out []Block
+	var para []string
+	var bullets []string
+
+	flushPara := func() {
+		if len(para) == 0 {
+			return
+		}
+		out = append(out, Block{Kind: Paragraph, Text: strings.Join(para, " ")})
+		para = nil
+	}
+
+	flushBullets := func() {
+		if len(bullets) == 0 {
+			return
+		}
+		out = append(out, Block{Kind: BulletList, Items: bullets})
+		bullets = nil
+	}
+
+	for _, ln := range strings.Split(s, "\n") {
+		t := strings.TrimRightFunc(ln, func(r rune) bool { return r == ' ' || r == '\t' })
+		ts := strings.TrimSpace(t)
+
+		switch {
+		case ts == "":
+			// Empty comment line => paragraph break
+			flushBullets()
+			flushPara()
+
+		case IsBullet(t):
+			// Bulleted item
+			flushPara()
+			bullets = append(bullets, TrimBullet(t))
+
+		default:
+			// Paragraph text
+			flushBullets()
+			para = append(para, ts)
+		}
+	}
+
+	flushBullets()
+	flushPara()
+	return out
+}
+
+// IsBullet returns true if the line is a bullet point, indented bullets count as well.
+func IsBullet(line string) bool {
+	t := strings.TrimLeft(line, " \t")
+	return strings.HasPrefix(t, "- ") || strings.HasPrefix(t, "* ") || strings.HasPrefix(t, "• ")
+}
+
+// TrimBullet returns the text of a bullet point without its marker.
+func TrimBullet(line string) string {
+	t := strings.TrimSpace(line)
+	return strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(t, "- "), "• "), "* ")
+}
@@ -0,0 +1,105 @@
+package crd_manifests
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"github.com/sourcehawk/cty-generator-addons/internal/yaml_docs"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a CustomResourceDefinition read from the YAML that CTY renders its docs from.
+type Manifest struct {
+	Group    string
+	Kind     string
+	Versions []Version
+	// File and Line locate the manifest document.
+	File string
+	Line int
+}
+
+// Version is a version declared in a CustomResourceDefinition.
+type Version struct {
+	Name   string
+	Served bool
+	// HasConditions is true if the schema of the version has a status.conditions field.
+	HasConditions bool
+}
+
+type crdDocument struct {
+	Kind string `yaml:"kind"`
+	Spec struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []struct {
+			Name   string `yaml:"name"`
+			Served bool   `yaml:"served"`
+			Schema struct {
+				OpenAPIV3Schema schemaProps `yaml:"openAPIV3Schema"`
+			} `yaml:"schema"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+type schemaProps struct {
+	Properties map[string]schemaProps `yaml:"properties"`
+}
+
+// LoadDir reads every CustomResourceDefinition from the .yaml, .yml and .json files below dir.
+// Documents of any other kind are skipped. Files that cannot be read are reported to diags.
+func LoadDir(dir string, diags *diagnostics.Collector) []Manifest {
+	var manifests []Manifest
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			diags.Errorf(diagnostics.Position{File: p}, "%v", err)
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		manifests = append(manifests, LoadFile(p, diags)...)
+		return nil
+	})
+	if err != nil {
+		diags.Errorf(diagnostics.Position{File: dir}, "%v", err)
+	}
+	return manifests
+}
+
+// LoadFile reads every CustomResourceDefinition from a (multi-document) YAML or JSON file. A document
+// that does not decode is reported to diags, the documents before it are still returned.
+func LoadFile(filename string, diags *diagnostics.Collector) []Manifest {
+	var manifests []Manifest
+	yaml_docs.DecodeFile(filename, diags, func(node *yaml.Node) {
+		var doc crdDocument
+		if err := node.Decode(&doc); err != nil {
+			diags.Errorf(diagnostics.Position{File: filename, Line: node.Line}, "%v", err)
+			return
+		}
+		if doc.Kind != "CustomResourceDefinition" || doc.Spec.Names.Kind == "" {
+			return
+		}
+
+		line := node.Line
+		if len(node.Content) > 0 {
+			line = node.Content[0].Line
+		}
+		m := Manifest{Group: doc.Spec.Group, Kind: doc.Spec.Names.Kind, File: filename, Line: line}
+		for _, v := range doc.Spec.Versions {
+			status := v.Schema.OpenAPIV3Schema.Properties["status"]
+			_, hasConditions := status.Properties["conditions"]
+			m.Versions = append(m.Versions, Version{Name: v.Name, Served: v.Served, HasConditions: hasConditions})
+		}
+		manifests = append(manifests, m)
+	})
+	return manifests
+}
@@ -0,0 +1,118 @@
+package crd_manifests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+)
+
+const clusterCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusters.example.com
+spec:
+  group: example.com
+  names:
+    kind: Cluster
+  versions:
+    - name: v1
+      served: true
+      schema:
+        openAPIV3Schema:
+          properties:
+            status:
+              properties:
+                conditions:
+                  type: array
+    - name: v1alpha1
+      served: false
+      schema:
+        openAPIV3Schema:
+          properties:
+            status:
+              properties:
+                phase:
+                  type: string
+`
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// want holds a line per manifest: <line> <group> <Kind> <version>(served,conditions) ...
+		want      []string
+		wantDiags []string // <line>: <part of the message>
+	}{
+		{
+			name:    "a CRD",
+			content: clusterCRD,
+			want:    []string{"1 example.com Cluster v1(served,conditions) v1alpha1()"},
+		},
+		{
+			name: "several documents, other kinds are skipped",
+			content: "kind: Namespace\nmetadata:\n  name: system\n---\n" + clusterCRD + "---\n" +
+				strings.ReplaceAll(clusterCRD, "Cluster", "Gateway"),
+			want: []string{
+				"5 example.com Cluster v1(served,conditions) v1alpha1()",
+				"33 example.com Gateway v1(served,conditions) v1alpha1()",
+			},
+		},
+		{
+			name:    "JSON",
+			content: `{"kind": "CustomResourceDefinition", "spec": {"group": "example.com", "names": {"kind": "Cluster"}}}`,
+			want:    []string{"1 example.com Cluster"},
+		},
+		{
+			name:      "a document that does not decode",
+			content:   "kind: CustomResourceDefinition\nspec:\n  versions: v1\n---\n" + clusterCRD,
+			want:      []string{"5 example.com Cluster v1(served,conditions) v1alpha1()"},
+			wantDiags: []string{"1: cannot unmarshal"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "crds.yaml")
+			if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			diags := diagnostics.NewCollector()
+
+			var got []string
+			for _, m := range LoadFile(filename, diags) {
+				if m.File != filename {
+					t.Errorf("manifest in %s, want %s", m.File, filename)
+				}
+				line := fmt.Sprintf("%d %s %s", m.Line, m.Group, m.Kind)
+				for _, v := range m.Versions {
+					var flags []string
+					if v.Served {
+						flags = append(flags, "served")
+					}
+					if v.HasConditions {
+						flags = append(flags, "conditions")
+					}
+					line += fmt.Sprintf(" %s(%s)", v.Name, strings.Join(flags, ","))
+				}
+				got = append(got, line)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("manifests\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+
+			gotDiags := diags.Diagnostics()
+			if len(gotDiags) != len(tt.wantDiags) {
+				t.Fatalf("diagnostics %v, want %v", gotDiags, tt.wantDiags)
+			}
+			for i, d := range gotDiags {
+				line, msg, _ := strings.Cut(tt.wantDiags[i], ": ")
+				if fmt.Sprint(d.Line) != line || !strings.Contains(d.Message, msg) {
+					t.Errorf("diagnostic %d: %s, want line %s: %s", d.Line, d.Message, line, msg)
+				}
+			}
+		})
+	}
+}
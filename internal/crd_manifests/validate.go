@@ -0,0 +1,191 @@
+package crd_manifests
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+// Validate checks that every documented CRD is defined by a manifest, and warns about conditions
+// documented for CRD versions without a status.conditions field and the other way round. Mismatches are
+// reported to diags, as errors if they make the documentation wrong, at every tag or catalog entry naming
+// the CRD.
+func Validate(manifests []Manifest, crds []model.CRD, diags *diagnostics.Collector) {
+	kinds := map[string][]Manifest{}
+	for _, m := range manifests {
+		kinds[m.Kind] = append(kinds[m.Kind], m)
+	}
+
+	for _, crd := range crds {
+		at := positions(crd)
+		candidates := kinds[crd.Name]
+		if len(candidates) == 0 {
+			msg := fmt.Sprintf("CRD %s is not defined by any CRD manifest", crd.Name)
+			if s := suggest(crd.Name, slices.Collect(maps.Keys(kinds))); len(s) > 0 {
+				msg += fmt.Sprintf(", did you mean %s?", strings.Join(s, " or "))
+			}
+			report(at, diags.Errorf, "%s", msg)
+			continue
+		}
+
+		if crd.Group != "" {
+			candidates = slices.DeleteFunc(slices.Clone(candidates), func(m Manifest) bool {
+				return m.Group != crd.Group
+			})
+			if len(candidates) == 0 {
+				report(
+					at, diags.Errorf, "CRD %s is not defined in group %s, it is defined in %s",
+					crd.Name, crd.Group, strings.Join(groupsOf(kinds[crd.Name]), ", "),
+				)
+				continue
+			}
+		}
+
+		if crd.Version == "" {
+			if len(crd.Conditions) > 0 && !slices.ContainsFunc(candidates, func(m Manifest) bool {
+				return slices.ContainsFunc(m.Versions, func(v Version) bool { return v.HasConditions })
+			}) {
+				report(
+					at, diags.Warnf, "conditions are documented for CRD %s, but none of its versions has a "+
+						"status.conditions field", crd.Name,
+				)
+			}
+			continue
+		}
+		var versions []string
+		var found *Version
+		for _, m := range candidates {
+			for i, v := range m.Versions {
+				versions = append(versions, v.Name)
+				if v.Name == crd.Version {
+					found = &m.Versions[i]
+				}
+			}
+		}
+		switch {
+		case found == nil:
+			msg := fmt.Sprintf(
+				"CRD %s has no version %s, declared versions are %s", crd.Name, crd.Version, strings.Join(versions, ", "),
+			)
+			if s := suggest(crd.Version, versions); len(s) > 0 {
+				msg += fmt.Sprintf(", did you mean %s?", strings.Join(s, " or "))
+			}
+			report(at, diags.Errorf, "%s", msg)
+		case !found.Served:
+			report(at, diags.Warnf, "version %s of CRD %s is documented but not served", crd.Version, crd.Name)
+		case !found.HasConditions && len(crd.Conditions) > 0:
+			report(
+				at, diags.Warnf, "conditions are documented for version %s of CRD %s, but it has no "+
+					"status.conditions field", crd.Version, crd.Name,
+			)
+		}
+	}
+
+	for _, m := range manifests {
+		for _, v := range m.Versions {
+			if !v.Served || !v.HasConditions || documented(crds, m, v) {
+				continue
+			}
+			diags.Warnf(
+				diagnostics.Position{File: m.File, Line: m.Line},
+				"CRD %s %s/%s has a status.conditions field but no documented conditions", m.Kind, m.Group, v.Name,
+			)
+		}
+	}
+}
+
+// positions returns where the conditions and reasons of the CRD are documented, so a problem with the
+// CRD points at the tags naming it. Shared reasons are left out as their wildcard tags do not name the
+// CRD, unless nothing else is documented.
+func positions(crd model.CRD) []diagnostics.Position {
+	var own, shared []diagnostics.Position
+	add := func(list *[]diagnostics.Position, p model.Provenance) {
+		pos := diagnostics.Position{File: p.File, Line: p.Line}
+		if p.File != "" && !slices.Contains(*list, pos) {
+			*list = append(*list, pos)
+		}
+	}
+	for _, c := range crd.Conditions {
+		add(&own, c.Provenance)
+		for _, r := range c.Reasons {
+			if r.Shared {
+				add(&shared, r.Provenance)
+			} else {
+				add(&own, r.Provenance)
+			}
+		}
+	}
+	switch {
+	case len(own) > 0:
+		return own
+	case len(shared) > 0:
+		return shared
+	}
+	return []diagnostics.Position{{}}
+}
+
+// report reports the problem at every position with the given Collector method.
+func report(at []diagnostics.Position, f func(diagnostics.Position, string, ...any), format string, a ...any) {
+	for _, pos := range at {
+		f(pos, format, a...)
+	}
+}
+
+// documented returns true if conditions are documented for the version of the manifest.
+func documented(crds []model.CRD, m Manifest, v Version) bool {
+	return slices.ContainsFunc(crds, func(crd model.CRD) bool {
+		return crd.Name == m.Kind &&
+			(crd.Group == "" || crd.Group == m.Group) &&
+			(crd.Version == "" || crd.Version == v.Name) &&
+			len(crd.Conditions) > 0
+	})
+}
+
+// suggest returns the candidates that are close enough to name to be a likely typo.
+func suggest(name string, candidates []string) []string {
+	var out []string
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(c))
+		if d <= max(2, len(name)/3) {
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func groupsOf(manifests []Manifest) []string {
+	var groups []string
+	for _, m := range manifests {
+		if !slices.Contains(groups, m.Group) {
+			groups = append(groups, m.Group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,169 @@
+package crd_manifests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+var manifests = []Manifest{
+	{
+		Group: "zeebe.example.com", Kind: "ZeebeCluster", File: "crds.yaml", Line: 1,
+		Versions: []Version{
+			{Name: "v1", Served: true, HasConditions: true},
+			{Name: "v1beta1", Served: true, HasConditions: true},
+			{Name: "v1alpha1", Served: false, HasConditions: true},
+		},
+	},
+	{
+		Group: "zeebe.example.com", Kind: "ZeebeGateway", File: "crds.yaml", Line: 40,
+		Versions: []Version{{Name: "v1", Served: true}},
+	},
+	{
+		Group: "operate.example.com", Kind: "Operate", File: "operate.yaml", Line: 1,
+		Versions: []Version{{Name: "v1", Served: true}},
+	},
+}
+
+// documentedCRD returns the CRD ref, <Kind> or <group>/<version>/<Kind>, with a condition documented at
+// api.go:line.
+func documentedCRD(ref string, line int) model.CRD {
+	var crd model.CRD
+	parts := strings.Split(ref, "/")
+	if len(parts) == 3 {
+		crd.Group, crd.Version = parts[0], parts[1]
+	}
+	crd.Name = parts[len(parts)-1]
+	crd.Conditions = []model.ConditionDoc{{
+		Name:       "Ready",
+		Provenance: model.Provenance{Origin: model.OriginSource, File: "api.go", Line: line},
+	}}
+	return crd
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		crds []model.CRD
+		// wantDiags holds a line per diagnostic: <severity> <file>:<line>: <part of its message>
+		wantDiags []string
+	}{
+		{
+			name: "documented versions",
+			crds: []model.CRD{
+				documentedCRD("zeebe.example.com/v1/ZeebeCluster", 10),
+				documentedCRD("zeebe.example.com/v1beta1/ZeebeCluster", 20),
+			},
+		},
+		{
+			name: "a Kind documented for every version",
+			crds: []model.CRD{documentedCRD("ZeebeCluster", 10)},
+		},
+		{
+			name: "unknown Kind",
+			crds: []model.CRD{documentedCRD("ZeebeClustr", 10), documentedCRD("Unrelated", 20)},
+			wantDiags: []string{
+				"error api.go:10: CRD ZeebeClustr is not defined by any CRD manifest, did you mean ZeebeCluster?",
+				"error api.go:20: CRD Unrelated is not defined by any CRD manifest",
+				"warning crds.yaml:1: CRD ZeebeCluster zeebe.example.com/v1 has a status.conditions field but",
+				"warning crds.yaml:1: CRD ZeebeCluster zeebe.example.com/v1beta1 has a status.conditions field",
+			},
+		},
+		{
+			name: "unknown group",
+			crds: []model.CRD{documentedCRD("zeebe.example.org/v1/ZeebeCluster", 10)},
+			wantDiags: []string{
+				"error api.go:10: CRD ZeebeCluster is not defined in group zeebe.example.org, it is defined in " +
+					"zeebe.example.com",
+				"warning crds.yaml:1: CRD ZeebeCluster zeebe.example.com/v1 has a status.conditions field but",
+				"warning crds.yaml:1: CRD ZeebeCluster zeebe.example.com/v1beta1 has a status.conditions field",
+			},
+		},
+		{
+			name: "unknown version",
+			crds: []model.CRD{
+				documentedCRD("zeebe.example.com/v2/ZeebeCluster", 10),
+				documentedCRD("zeebe.example.com/v1betal/ZeebeCluster", 20),
+				documentedCRD("ZeebeCluster", 30),
+			},
+			wantDiags: []string{
+				"error api.go:10: CRD ZeebeCluster has no version v2, declared versions are v1, v1beta1, v1alpha1, " +
+					"did you mean v1?",
+				"error api.go:20: did you mean v1beta1?",
+			},
+		},
+		{
+			name: "version not served",
+			crds: []model.CRD{
+				documentedCRD("zeebe.example.com/v1alpha1/ZeebeCluster", 10),
+				documentedCRD("ZeebeCluster", 20),
+			},
+			wantDiags: []string{"warning api.go:10: version v1alpha1 of CRD ZeebeCluster is documented but not served"},
+		},
+		{
+			name: "conditions of CRDs without a status.conditions field",
+			crds: []model.CRD{
+				documentedCRD("zeebe.example.com/v1/ZeebeGateway", 10),
+				documentedCRD("Operate", 20),
+				documentedCRD("ZeebeCluster", 30),
+			},
+			wantDiags: []string{
+				"warning api.go:10: conditions are documented for version v1 of CRD ZeebeGateway, but it has no " +
+					"status.conditions field",
+				"warning api.go:20: conditions are documented for CRD Operate, but none of its versions has",
+			},
+		},
+		{
+			name: "a CRD without conditions",
+			crds: []model.CRD{{Name: "ZeebeCluster"}, {Name: "ZeebeGatway"}},
+			wantDiags: []string{
+				"error : CRD ZeebeGatway is not defined by any CRD manifest, did you mean ZeebeGateway?",
+				"warning crds.yaml:1: CRD ZeebeCluster zeebe.example.com/v1 has a status.conditions field but",
+				"warning crds.yaml:1: CRD ZeebeCluster zeebe.example.com/v1beta1 has a status.conditions field",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := diagnostics.NewCollector()
+			Validate(manifests, tt.crds, diags)
+
+			gotDiags := diags.Diagnostics()
+			if len(gotDiags) != len(tt.wantDiags) {
+				t.Fatalf("diagnostics %v, want %v", gotDiags, tt.wantDiags)
+			}
+			for i, d := range gotDiags {
+				at, msg, _ := strings.Cut(tt.wantDiags[i], ": ")
+				got := fmt.Sprintf("%s %s:%d", d.Severity, d.File, d.Line)
+				if d.File == "" {
+					got = fmt.Sprintf("%s ", d.Severity)
+				}
+				if got != at || !strings.Contains(d.Message, msg) {
+					t.Errorf("diagnostic %s: %s, want %s: %s", got, d.Message, at, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       []string
+	}{
+		{"Gatway", []string{"Gateway", "Cluster"}, []string{"Gateway"}},
+		{"gateway", []string{"Gateway"}, []string{"Gateway"}},
+		{"Broker", []string{"Gateway", "Cluster"}, nil},
+		{"Gateways", []string{"Gateway", "Gateways2", "Cluster"}, []string{"Gateway", "Gateways2"}},
+		{"v1beta", []string{"v1", "v1beta1", "v1alpha1"}, []string{"v1beta1"}},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.name, tt.candidates); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("suggest(%q, %v) = %v, want %v", tt.name, tt.candidates, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package diagnostics
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Position locates a diagnostic in a file. Line and Column are 1-based, zero if unknown.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	switch {
+	case p.File == "":
+		return ""
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+	// Frame is the source line the diagnostic points at with a caret under the column, if available.
+	Frame string `json:"frame,omitempty"`
+}
+
+func (d Diagnostic) Position() Position {
+	return Position{File: d.File, Line: d.Line, Column: d.Column}
+}
+
+// Collector gathers the diagnostics of a run so that all of them can be reported at once.
+type Collector struct {
+	diagnostics []Diagnostic
+	// sources caches the lines of the files that code frames are built from.
+	sources map[string][]string
+}
+
+func NewCollector() *Collector {
+	return &Collector{sources: map[string][]string{}}
+}
+
+// Report adds a diagnostic at pos, pos may be the zero Position for diagnostics without a location.
+func (c *Collector) Report(severity Severity, pos Position, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Severity: severity,
+		File:     pos.File,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Message:  fmt.Sprintf(format, args...),
+		Frame:    c.frame(pos),
+	})
+}
+
+func (c *Collector) Errorf(pos Position, format string, args ...any) {
+	c.Report(SeverityError, pos, format, args...)
+}
+
+func (c *Collector) Warnf(pos Position, format string, args ...any) {
+	c.Report(SeverityWarning, pos, format, args...)
+}
+
+func (c *Collector) Infof(pos Position, format string, args ...any) {
+	c.Report(SeverityInfo, pos, format, args...)
+}
+
+// HasErrors returns true if any diagnostic with SeverityError was reported.
+func (c *Collector) HasErrors() bool {
+	for _, d := range c.diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnostics returns the reported diagnostics ordered by file and position. Diagnostics without a
+// file come first, in the order they were reported.
+func (c *Collector) Diagnostics() []Diagnostic {
+	out := append([]Diagnostic{}, c.diagnostics...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return out
+}
+
+// frame renders the source line at pos with a caret under the column.
+func (c *Collector) frame(pos Position) string {
+	if pos.File == "" || pos.Line == 0 {
+		return ""
+	}
+	lines, ok := c.sources[pos.File]
+	if !ok {
+		data, err := os.ReadFile(pos.File)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		c.sources[pos.File] = lines
+	}
+	if pos.Line > len(lines) {
+		return ""
+	}
+
+	line := strings.TrimRight(lines[pos.Line-1], "\r")
+	gutter := fmt.Sprintf("%d | ", pos.Line)
+	frame := gutter + line
+	if pos.Column > 0 && pos.Column <= len(line)+1 {
+		// keep the tabs of the source line so the caret lines up
+		var pad strings.Builder
+		for _, r := range line[:pos.Column-1] {
+			if r == '\t' {
+				pad.WriteRune('\t')
+			} else {
+				pad.WriteRune(' ')
+			}
+		}
+		frame += "\n" + strings.Repeat(" ", len(gutter)-2) + "| " + pad.String() + "^"
+	}
+	return frame
+}
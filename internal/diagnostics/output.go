@@ -0,0 +1,145 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formats supported by Write.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Write writes the diagnostics in the given format: text for humans, json or sarif for tools.
+func Write(w io.Writer, format string, diags []Diagnostic) error {
+	switch format {
+	case FormatText:
+		return WriteText(w, diags)
+	case FormatJSON:
+		return WriteJSON(w, diags)
+	case FormatSARIF:
+		return WriteSARIF(w, diags)
+	}
+	return fmt.Errorf("unknown diagnostics format %q, expected text, json or sarif", format)
+}
+
+// WriteText writes one "file:line:col: severity: message" line per diagnostic followed by its code
+// frame, and a summary line.
+func WriteText(w io.Writer, diags []Diagnostic) error {
+	counts := map[Severity]int{}
+	for _, d := range diags {
+		counts[d.Severity]++
+		var b strings.Builder
+		if pos := d.Position().String(); pos != "" {
+			b.WriteString(pos + ": ")
+		}
+		b.WriteString(string(d.Severity) + ": " + d.Message + "\n")
+		if d.Frame != "" {
+			b.WriteString(d.Frame + "\n")
+		}
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	if len(diags) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%d error(s), %d warning(s)\n", counts[SeverityError], counts[SeverityWarning])
+	return err
+}
+
+// WriteJSON writes the diagnostics as {"diagnostics": [...]}.
+func WriteJSON(w io.Writer, diags []Diagnostic) error {
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{"diagnostics": diags})
+}
+
+// SARIF 2.1.0, limited to what code scanning tools and editors read.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+	} `json:"driver"`
+}
+
+type sarifResult struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the diagnostics as a SARIF 2.1.0 log.
+func WriteSARIF(w io.Writer, diags []Diagnostic) error {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "cty-conditions-addon"
+	run.Tool.Driver.InformationURI = "https://github.com/sourcehawk/cty-generator-addons"
+
+	for _, d := range diags {
+		res := sarifResult{Level: sarifLevel(d.Severity), Message: sarifMessage{Text: d.Message}}
+		if d.File != "" {
+			var loc sarifLocation
+			loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(d.File)
+			if d.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+			}
+			res.Locations = append(res.Locations, loc)
+		}
+		run.Results = append(run.Results, res)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
@@ -0,0 +1,140 @@
+package export
+
+// The versioned, machine-readable form of the documentation model. The types of this package are the
+// published format described by docs/conditions-export.schema.json: fields may be added, but not
+// renamed or removed without bumping APIVersion.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// APIVersion identifies the version of the export format.
+const APIVersion = "cty-conditions.sourcehawk.github.com/v1"
+
+// Formats supported by Write.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+type Document struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	CRDs       []CRD  `json:"crds" yaml:"crds"`
+}
+
+type CRD struct {
+	Kind       string      `json:"kind" yaml:"kind"`
+	Group      string      `json:"group,omitempty" yaml:"group,omitempty"`
+	Version    string      `json:"version,omitempty" yaml:"version,omitempty"`
+	Conditions []Condition `json:"conditions" yaml:"conditions"`
+}
+
+type Condition struct {
+	Name        string   `json:"name" yaml:"name"`
+	ConstName   string   `json:"constName,omitempty" yaml:"constName,omitempty"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	DisplayName string   `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	Polarity    string   `json:"polarity,omitempty" yaml:"polarity,omitempty"`
+	Severity    string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Source      *Source  `json:"source,omitempty" yaml:"source,omitempty"`
+	Reasons     []Reason `json:"reasons" yaml:"reasons"`
+}
+
+type Reason struct {
+	Name        string  `json:"name" yaml:"name"`
+	ConstName   string  `json:"constName,omitempty" yaml:"constName,omitempty"`
+	DisplayName string  `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	Severity    string  `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Shared      bool    `json:"shared,omitempty" yaml:"shared,omitempty"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Source      *Source `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// Source tells where a condition or reason was documented, and for tagged Go declarations which one.
+type Source struct {
+	Origin     string `json:"origin" yaml:"origin"`
+	File       string `json:"file,omitempty" yaml:"file,omitempty"`
+	Line       int    `json:"line,omitempty" yaml:"line,omitempty"`
+	URL        string `json:"url,omitempty" yaml:"url,omitempty"`
+	ImportPath string `json:"importPath,omitempty" yaml:"importPath,omitempty"`
+	Ident      string `json:"ident,omitempty" yaml:"ident,omitempty"`
+	Kind       string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	TypeName   string `json:"typeName,omitempty" yaml:"typeName,omitempty"`
+}
+
+// FromModel converts the documentation model to the export format.
+func FromModel(crds []model.CRD) Document {
+	doc := Document{APIVersion: APIVersion, CRDs: []CRD{}}
+	for _, crd := range crds {
+		c := CRD{Kind: crd.Name, Group: crd.Group, Version: crd.Version, Conditions: []Condition{}}
+		for _, cond := range crd.Conditions {
+			ec := Condition{
+				Name:        cond.Name,
+				ConstName:   cond.ConstName,
+				Type:        cond.Type,
+				DisplayName: cond.DisplayName,
+				Polarity:    cond.Polarity,
+				Severity:    cond.Severity,
+				Description: cond.Description,
+				Source:      source(cond.Provenance, cond.Decl),
+				Reasons:     []Reason{},
+			}
+			for _, r := range cond.Reasons {
+				ec.Reasons = append(ec.Reasons, Reason{
+					Name:        r.Name,
+					ConstName:   r.ConstName,
+					DisplayName: r.DisplayName,
+					Severity:    r.Severity,
+					Shared:      r.Shared,
+					Description: r.Description,
+					Source:      source(r.Provenance, r.Decl),
+				})
+			}
+			c.Conditions = append(c.Conditions, ec)
+		}
+		doc.CRDs = append(doc.CRDs, c)
+	}
+	return doc
+}
+
+// source returns nil for conditions that were only named by reasons.
+func source(p model.Provenance, d model.GoDecl) *Source {
+	if p.Origin == "" {
+		return nil
+	}
+	return &Source{
+		Origin:     p.Origin,
+		File:       p.File,
+		Line:       p.Line,
+		URL:        d.URL,
+		ImportPath: d.ImportPath,
+		Ident:      d.Ident,
+		Kind:       d.Kind,
+		TypeName:   d.TypeName,
+	}
+}
+
+// Write writes the model as a JSON or YAML export document.
+func Write(w io.Writer, format string, crds []model.CRD) error {
+	doc := FromModel(crds)
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unknown export format %q, expected json or yaml", format)
+}
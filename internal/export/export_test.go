@@ -0,0 +1,217 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// documented sets every field of the model that is exported.
+var documented = []model.CRD{
+	{
+		Name: "ZeebeCluster", Group: "zeebe.example.com", Version: "v1",
+		Conditions: []model.ConditionDoc{
+			{
+				Name: "Ready", ConstName: "ClusterReady", Type: "string", DisplayName: "Cluster ready",
+				Polarity: "positive", Severity: "Error", Description: "Whether the cluster is ready.",
+				Provenance: model.Provenance{Origin: model.OriginSource, File: "api/v1/types.go", Line: 12},
+				Decl: model.GoDecl{
+					URL: "https://example.com/api/v1/types.go#L12", ImportPath: "example.com/op/api/v1",
+					Ident: "v1.ClusterReady", Kind: "const", TypeName: "ConditionType",
+				},
+				Reasons: []model.ReasonDoc{{
+					Name: "Starting", ConstName: "ReasonStarting", DisplayName: "Starting", Severity: "Info",
+					Shared: true, Description: "The cluster is starting.",
+					Provenance: model.Provenance{Origin: model.OriginCatalog, File: "catalog.yaml", Line: 3},
+				}, {
+					Name: "Failed", ConstName: "ReasonFailed",
+					Provenance: model.Provenance{Origin: model.OriginSource, File: "api/v1/types.go", Line: 20},
+					Decl: model.GoDecl{
+						URL: "https://example.com/api/v1/types.go#L20", ImportPath: "example.com/op/api/v1",
+						Ident: "v1.ReasonFailed", Kind: "const", TypeName: "string",
+					},
+				}},
+			},
+			// only named by a reason
+			{Name: "Synced", Type: "string"},
+		},
+	},
+	{Name: "Gateway"},
+}
+
+func loadSchema(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile("../../docs/conditions-export.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestWriteMatchesSchema(t *testing.T) {
+	schema := loadSchema(t)
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, documented); err != nil {
+				t.Fatal(err)
+			}
+			var doc any
+			var err error
+			if format == FormatJSON {
+				err = json.Unmarshal(buf.Bytes(), &doc)
+			} else {
+				err = yaml.Unmarshal(buf.Bytes(), &doc)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := validator{root: schema, seen: map[string]bool{}}
+			v.validate("", schema, doc)
+			for _, e := range v.errs {
+				t.Error(e)
+			}
+			// every property of the schema is written when it is set, so none was renamed on one side only
+			for _, p := range v.properties(schema, "") {
+				if !v.seen[p] {
+					t.Errorf("%s is in the schema but not in the document", p)
+				}
+			}
+		})
+	}
+}
+
+func TestValidator(t *testing.T) {
+	doc := map[string]any{
+		"apiVersion": "v0",
+		"crds":       []any{map[string]any{"kind": "Cluster", "conditions": []any{}, "Group": "example.com"}},
+	}
+	schema := loadSchema(t)
+	v := validator{root: schema, seen: map[string]bool{}}
+	v.validate("", schema, doc)
+	sort.Strings(v.errs)
+	want := []string{"apiVersion: v0, want " + APIVersion, "crds: unknown property Group"}
+	if !slices.Equal(v.errs, want) {
+		t.Errorf("errors %q, want %q", v.errs, want)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "toml", documented); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+// validator checks a document against the parts of JSON schema docs/conditions-export.schema.json uses.
+type validator struct {
+	root map[string]any
+	errs []string
+	seen map[string]bool // the properties present in the document, by their schema path, e.g. crds.kind
+}
+
+func (v *validator) resolve(schema map[string]any) map[string]any {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		return v.root["$defs"].(map[string]any)[name].(map[string]any)
+	}
+	return schema
+}
+
+func (v *validator) errorf(path, format string, a ...any) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, a...))
+}
+
+func (v *validator) validate(path string, schema map[string]any, value any) {
+	schema = v.resolve(schema)
+	if c, ok := schema["const"]; ok && value != c {
+		v.errorf(path, "%v, want %v", value, c)
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		v.errorf(path, "%v, want one of %v", value, enum)
+	}
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.errorf(path, "%T, want an object", value)
+			return
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for _, r := range schema["required"].([]any) {
+			if _, ok := obj[r.(string)]; !ok {
+				v.errorf(path, "missing required %s", r)
+			}
+		}
+		for key, val := range obj {
+			p, ok := props[key]
+			if !ok {
+				v.errorf(path, "unknown property %s", key)
+				continue
+			}
+			v.seen[strings.TrimPrefix(path+"."+key, ".")] = true
+			v.validate(strings.TrimPrefix(path+"."+key, "."), p.(map[string]any), val)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			v.errorf(path, "%T, want an array", value)
+			return
+		}
+		for _, item := range items {
+			v.validate(path, schema["items"].(map[string]any), item)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			v.errorf(path, "%T, want a string", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.errorf(path, "%T, want a boolean", value)
+		}
+	case "integer":
+		var n float64
+		switch i := value.(type) {
+		case int:
+			n = float64(i)
+		case float64:
+			n = i
+		default:
+			v.errorf(path, "%T, want an integer", value)
+			return
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			v.errorf(path, "%v, want at least %v", n, min)
+		}
+	}
+}
+
+// properties returns the schema paths of all properties below schema.
+func (v *validator) properties(schema map[string]any, path string) []string {
+	schema = v.resolve(schema)
+	if items, ok := schema["items"].(map[string]any); ok {
+		return v.properties(items, path)
+	}
+	var out []string
+	props, _ := schema["properties"].(map[string]any)
+	for key, prop := range props {
+		p := strings.TrimPrefix(path+"."+key, ".")
+		out = append(out, p)
+		out = append(out, v.properties(prop.(map[string]any), p)...)
+	}
+	sort.Strings(out)
+	return out
+}
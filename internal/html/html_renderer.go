@@ -5,6 +5,8 @@ import (
 	"html"
 	"html/template"
 	"strings"
+
+	cf "github.com/sourcehawk/cty-generator-addons/internal/comment_format"
 )
 
 type Generator interface {
@@ -53,79 +55,54 @@ func (g *BaseHTMLGenerator) ExecTemplate(name string, data any) (template.HTML,
 // Converts plain text (with lines starting `-`, `•`, or `*`) into safe <p>/<ul>/<li> HTML.
 // Empty lines create paragraph breaks. Consecutive non-bullet lines become a single paragraph.
 func formatCommentHTML(s string) string {
-	if s == "" {
-		return ""
-	}
-	lines := strings.Split(s, "\n")
-
 	var out []string
-	var para []string
-	var bullets []string
-
-	isBullet := func(t string) bool {
-		// treat indented bullets as bullets too
-		t = strings.TrimLeft(t, " \t")
-		return strings.HasPrefix(t, "- ") || strings.HasPrefix(t, "* ") || strings.HasPrefix(t, "• ")
-	}
-
-	flushPara := func() {
-		if len(para) == 0 {
-			return
+	for _, b := range cf.Parse(s) {
+		switch b.Kind {
+		case cf.Paragraph:
+			out = append(out, "<p>"+html.EscapeString(b.Text)+"</p>")
+		case cf.BulletList:
+			out = append(out, "<ul>")
+			for _, item := range b.Items {
+				out = append(out, "<li>"+html.EscapeString(item)+"</li>")
+			}
+			out = append(out, "</ul>")
 		}
-		// join with a single space so wrapped comments become one paragraph
-		text := html.EscapeString(strings.Join(para, " "))
-		out = append(out, "<p>"+text+"</p>")
-		para = nil
 	}
+	return strings.Join(out, "\n")
+}
 
-	flushBullets := func() {
-		if len(bullets) == 0 {
-			return
-		}
-		out = append(out, "<ul>")
-		for _, b := range bullets {
-			// remove leading marker once more (handles mixed indents)
-			bt := strings.TrimSpace(b)
-			bt = strings.TrimLeft(bt, " \t")
-			bt = strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(bt, "- "), "• "), "* ")
-			out = append(out, "<li>"+html.EscapeString(bt)+"</li>")
-		}
-		out = append(out, "</ul>")
-		bullets = nil
+// Renders the badge telling whether a condition being True is normal or abnormal, empty if unset.
+func polarityBadgeHTML(polarity string) string {
+	switch polarity {
+	case "positive":
+		return `<span class="property-type cty-badge cty-polarity-positive" title="True is the normal state">Normal when True</span>`
+	case "negative":
+		return `<span class="property-type cty-badge cty-polarity-negative" title="True is an abnormal state">Abnormal when True</span>`
 	}
+	return ""
+}
 
-	for _, ln := range lines {
-		t := strings.TrimRightFunc(ln, func(r rune) bool { return r == ' ' || r == '\t' })
-		ts := strings.TrimSpace(t)
-
-		switch {
-		case ts == "":
-			// Empty comment line => paragraph break
-			flushBullets()
-			flushPara()
-
-		case isBullet(t):
-			// Bulleted item
-			flushPara()
-			bullets = append(bullets, t)
-
-		default:
-			// Paragraph text
-			flushBullets()
-			para = append(para, ts)
-		}
+// Renders the severity badge of a condition or reason, empty if unset.
+func severityBadgeHTML(severity string) string {
+	if severity == "" {
+		return ""
 	}
-
-	flushBullets()
-	flushPara()
-	return strings.Join(out, "\n")
+	class := "cty-severity-" + strings.ToLower(severity)
+	return `<span class="property-type cty-badge ` + html.EscapeString(class) + `">` +
+		html.EscapeString(severity) + `</span>`
 }
 
-// Make the helper available to templates (return template.HTML so it doesn't get escaped again)
+// Make the helpers available to templates (return template.HTML so it doesn't get escaped again)
 var tmplFuncs = template.FuncMap{
 	"formatComment": func(s string) template.HTML {
 		return template.HTML(formatCommentHTML(s))
 	},
+	"polarityBadge": func(s string) template.HTML {
+		return template.HTML(polarityBadgeHTML(s))
+	},
+	"severityBadge": func(s string) template.HTML {
+		return template.HTML(severityBadgeHTML(s))
+	},
 }
 
 func MustParseTemplate(localName, src string) *template.Template {
@@ -0,0 +1,394 @@
+package html
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Comments around the injected fragment, so that a later run finds and replaces it.
+const (
+	BeginMarker = "cty-conditions:begin"
+	EndMarker   = "cty-conditions:end"
+)
+
+// Placement tells where the fragment goes relative to the target element.
+type Placement string
+
+const (
+	Append  Placement = "append"  // as the last child of the target
+	Prepend Placement = "prepend" // as the first child of the target
+	Before  Placement = "before"  // in front of the target
+	After   Placement = "after"   // behind the target
+	Replace Placement = "replace" // instead of the target
+)
+
+// ParsePlacement parses the name of a Placement.
+func ParsePlacement(s string) (Placement, error) {
+	switch p := Placement(s); p {
+	case Append, Prepend, Before, After, Replace:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid placement %q, expected append, prepend, before, after or replace", s)
+}
+
+// Target selects where a fragment is injected. If the selector matches several elements, the last one
+// is used unless First is set.
+type Target struct {
+	Selector  *Selector
+	Placement Placement
+	First     bool
+	// KeepPerCRD keeps the fragments InjectPerCRD placed, for a section of the CRDs it found no block for.
+	KeepPerCRD bool
+}
+
+// Inject places fragment at target in base, wrapped in marker comments. A fragment injected by an
+// earlier run is removed first, so running again does not duplicate it, as are the fragments of
+// InjectPerCRD unless target.KeepPerCRD is set. If the target no longer exists, e.g. because it was
+// replaced, the fragment replaces the earlier one in place.
+//
+// base is only tokenized to find the offsets to splice at, everything outside the injected region is
+// returned byte for byte as it was.
+func Inject(base, fragment string, target Target) (string, error) {
+	doc, err := scan(base, target.Selector)
+	if err != nil {
+		return "", err
+	}
+
+	injected := wrap("", fragment)
+
+	var old *span
+	if r := doc.region(""); r != nil {
+		old = &r.span
+	} else if doc.legacy != nil {
+		// a section injected before the markers were introduced
+		old = doc.legacy
+	}
+
+	matches := doc.matches[0]
+	if len(matches) == 0 {
+		if doc.region("") == nil {
+			return "", fmt.Errorf("no element matches %s", target.Selector)
+		}
+		return base[:old.start] + injected + base[old.end:], nil
+	}
+	el := matches[len(matches)-1]
+	if target.First {
+		el = matches[0]
+	}
+
+	e, err := placeAt(el, target.Placement, injected)
+	if err != nil {
+		return "", err
+	}
+	edits := []edit{e}
+	if old != nil {
+		switch {
+		case old.start <= e.start && e.end <= old.end && e.start != e.end:
+			return "", errors.New("the target is part of the previously injected section")
+		case e.start <= old.start && old.end <= e.end:
+			// replaced along with the target
+		case old.start < e.start && e.start < old.end:
+			return "", errors.New("the target overlaps the previously injected section")
+		default:
+			edits = append(edits, edit{old.start, old.end, ""})
+		}
+	}
+	// the fragments InjectPerCRD placed in the blocks of the CRDs
+	for _, r := range doc.regions {
+		if target.KeepPerCRD || !isPerCRD(r.args) {
+			continue
+		}
+		if !(e.start <= r.start && r.end <= e.end) {
+			edits = append(edits, edit{r.start, r.end, ""})
+		}
+	}
+	return applyEdits(base, edits), nil
+}
+
+// wrap puts the marker comments around fragment, args is appended to the begin marker.
+func wrap(args, fragment string) string {
+	begin := BeginMarker
+	if args != "" {
+		begin += " " + args
+	}
+	return "<!-- " + begin + " -->" + fragment + "<!-- " + EndMarker + " -->"
+}
+
+// placeAt returns the edit inserting text at placement relative to el.
+func placeAt(el *elementSpan, placement Placement, text string) (edit, error) {
+	switch placement {
+	case Append, Prepend:
+		if el.void {
+			return edit{}, fmt.Errorf("cannot %s to <%s>, it has no content", placement, el.tag)
+		}
+		if placement == Prepend {
+			return edit{el.openEnd, el.openEnd, text}, nil
+		}
+		return edit{el.closeStart, el.closeStart, text}, nil
+	case Before:
+		return edit{el.start, el.start, text}, nil
+	case After:
+		return edit{el.end, el.end, text}, nil
+	case Replace:
+		return edit{el.start, el.end, text}, nil
+	}
+	return edit{}, fmt.Errorf("invalid placement %q", placement)
+}
+
+// applyEdits applies non-overlapping edits to doc. They are applied from the end, so that the offsets
+// of the earlier edits stay valid; a removal starting where text is inserted goes first, so it does not
+// remove the inserted text.
+func applyEdits(doc string, edits []edit) string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	for _, e := range edits {
+		doc = doc[:e.start] + e.text + doc[e.end:]
+	}
+	return doc
+}
+
+// edit replaces the bytes [start, end) of a document with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// span is the byte range [start, end) of a token or element in a document.
+type span struct {
+	start, end int
+}
+
+// elementSpan locates an element: its start tag is [start, openEnd), its end tag [closeStart, end). An
+// element without an end tag ends where the element closing it starts.
+type elementSpan struct {
+	tag        string
+	void       bool
+	start      int
+	openEnd    int
+	closeStart int
+	end        int
+	text       string // the unescaped text content, only recorded for matched elements
+}
+
+// contains returns true if the element contains the byte range sp.
+func (e *elementSpan) contains(sp span) bool {
+	return e.openEnd <= sp.start && sp.end <= e.closeStart
+}
+
+// region is a fragment injected by an earlier run, from its begin marker to its end marker.
+type region struct {
+	span
+	args string // what follows BeginMarker in the begin marker, e.g. "crd=example.com/v1/Foo"
+}
+
+// scanned holds the offsets in a document that injection needs.
+type scanned struct {
+	// matches[i] are the closed elements matching the i-th selector, outside the injected regions
+	matches [][]*elementSpan
+	regions []region
+	legacy  *span // the card of a section injected without markers, with the <style> before it
+}
+
+// region returns the injected region with the given marker arguments, nil if there is none.
+func (s *scanned) region(args string) *region {
+	for i := range s.regions {
+		if s.regions[i].args == args {
+			return &s.regions[i]
+		}
+	}
+	return nil
+}
+
+// voidElements have no content and no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// scan tokenizes the document and records the offsets of the elements each of sels matches, the marker
+// comments and a section injected without markers. Open elements are tracked on a stack, an end tag
+// closes the nearest open element of its name and those opened after it.
+func scan(doc string, sels ...*Selector) (*scanned, error) {
+	s := scanned{matches: make([][]*elementSpan, len(sels))}
+	type open struct {
+		element
+		span    *elementSpan
+		matched []int // indexes of the selectors matching the element
+		text    *strings.Builder
+	}
+	var stack []open
+	var legacyCard *elementSpan
+	// the last </style> and the <style> it closes, the style of a legacy section precedes its card
+	styleStart, lastStyleStart, lastStyleEnd := -1, -1, -1
+	// the begin marker of the region being scanned
+	var begin *region
+
+	ancestors := func() []element {
+		out := make([]element, len(stack))
+		for i, o := range stack {
+			out[i] = o.element
+		}
+		return out
+	}
+	closeElement := func(o open, closeStart, end int) {
+		o.span.closeStart, o.span.end = closeStart, end
+		if o.text != nil {
+			o.span.text = o.text.String()
+		}
+		for _, i := range o.matched {
+			s.matches[i] = append(s.matches[i], o.span)
+		}
+		if o.span == legacyCard {
+			s.legacy = &span{start: o.span.start, end: end}
+			if lastStyleEnd >= 0 && strings.TrimSpace(doc[lastStyleEnd:o.span.start]) == "" {
+				s.legacy.start = lastStyleStart
+			}
+		}
+	}
+
+	z := html.NewTokenizer(strings.NewReader(doc))
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if errors.Is(z.Err(), io.EOF) {
+				break
+			}
+			return nil, z.Err()
+		}
+		start := offset
+		offset += len(z.Raw())
+		tok := z.Token()
+
+		switch tt {
+		case html.CommentToken:
+			data := strings.TrimSpace(tok.Data)
+			switch {
+			case data == BeginMarker || strings.HasPrefix(data, BeginMarker+" "):
+				if begin != nil {
+					return nil, errors.New("found a " + BeginMarker + " comment inside another injected section")
+				}
+				begin = &region{
+					span: span{start: start},
+					args: strings.TrimSpace(strings.TrimPrefix(data, BeginMarker)),
+				}
+			case data == EndMarker && begin != nil:
+				begin.end = offset
+				s.regions = append(s.regions, *begin)
+				begin = nil
+			}
+
+		case html.TextToken:
+			for _, o := range stack {
+				if o.text != nil {
+					o.text.WriteString(tok.Data)
+				}
+			}
+		}
+		if tt == html.StartTagToken || tt == html.EndTagToken {
+			// keep the text of neighbouring elements apart
+			for _, o := range stack {
+				if o.text != nil {
+					o.text.WriteByte(' ')
+				}
+			}
+		}
+
+		switch tt {
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if tok.Data == "style" && tt == html.StartTagToken {
+				styleStart = start
+			}
+			e := element{tag: tok.Data, attrs: tok.Attr}
+			o := open{
+				element: e,
+				span:    &elementSpan{tag: tok.Data, start: start, openEnd: offset},
+			}
+			if begin == nil {
+				for i, sel := range sels {
+					if sel.matches(e, ancestors()) {
+						o.matched = append(o.matched, i)
+					}
+				}
+				if len(o.matched) > 0 {
+					o.text = &strings.Builder{}
+				}
+			}
+			// the accordion of a section injected without markers
+			if hasAttr(tok.Attr, "id", "conditions-root") && legacyCard == nil && begin == nil {
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i].tag == "div" && hasClass(stack[i].attrs, "card") {
+						legacyCard = stack[i].span
+						break
+					}
+				}
+			}
+			if voidElements[tok.Data] || tt == html.SelfClosingTagToken {
+				o.span.void = voidElements[tok.Data]
+				closeElement(o, offset, offset)
+				continue
+			}
+			stack = append(stack, o)
+
+		case html.EndTagToken:
+			if tok.Data == "style" {
+				lastStyleStart, lastStyleEnd = styleStart, offset
+			}
+			i := len(stack) - 1
+			for i >= 0 && stack[i].tag != tok.Data {
+				i--
+			}
+			if i < 0 {
+				continue // stray end tag
+			}
+			for j := len(stack) - 1; j > i; j-- {
+				closeElement(stack[j], start, start)
+			}
+			closeElement(stack[i], start, offset)
+			stack = stack[:i]
+		}
+	}
+	if begin != nil {
+		return nil, errors.New("found a " + BeginMarker + " comment without a matching " + EndMarker)
+	}
+	for j := len(stack) - 1; j >= 0; j-- {
+		closeElement(stack[j], len(doc), len(doc))
+	}
+	for _, m := range s.matches {
+		sort.SliceStable(m, func(i, j int) bool { return m[i].start < m[j].start })
+	}
+	return &s, nil
+}
+
+func hasClass(attrs []html.Attribute, class string) bool {
+	for _, a := range attrs {
+		if a.Key != "class" {
+			continue
+		}
+		for _, c := range strings.Fields(a.Val) {
+			if c == class {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasAttr(attrs []html.Attribute, key, val string) bool {
+	for _, a := range attrs {
+		if a.Key == key && a.Val == val {
+			return true
+		}
+	}
+	return false
+}
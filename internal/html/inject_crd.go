@@ -0,0 +1,180 @@
+package html
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CRDFragment is the documentation of a single CRD, injected into the block CTY renders for it.
+type CRDFragment struct {
+	Kind    string
+	Group   string // empty if unknown
+	Version string // empty if unknown
+	HTML    string
+}
+
+// markerArgs returns the arguments of the begin marker of the fragment, e.g.
+// "crd=example.com/v1/Foo".
+func (f CRDFragment) markerArgs() string {
+	if f.Group == "" && f.Version == "" {
+		return "crd=" + f.Kind
+	}
+	return "crd=" + f.Group + "/" + f.Version + "/" + f.Kind
+}
+
+func (f CRDFragment) String() string {
+	return strings.TrimPrefix(f.markerArgs(), "crd=")
+}
+
+// CRDTargets selects the blocks CTY renders per CRD. The block of a CRD is the one whose header, the
+// first element matching Header inside it, names the Kind of the CRD.
+type CRDTargets struct {
+	Block     *Selector
+	Header    *Selector
+	Placement Placement // relative to the block, Replace is not allowed
+}
+
+// crdSharedArgs are the arguments of the begin marker of what the fragments of InjectPerCRD share.
+const crdSharedArgs = "crds"
+
+// isPerCRD returns true for the arguments of the begin markers InjectPerCRD writes.
+func isPerCRD(args string) bool {
+	return args == crdSharedArgs || strings.HasPrefix(args, "crd=")
+}
+
+// InjectPerCRD places every fragment in the block of its CRD, wrapped in marker comments naming the CRD.
+// A header names a Kind if the Kind is one of its words; if several blocks name it, the one whose header
+// also names the group and version of the CRD wins, the first one on a tie.
+//
+// shared, e.g. the styles the fragments rely on, is injected once, in front of the first fragment placed.
+//
+// Fragments injected by an earlier run are removed first, as is a section injected by Inject, so that
+// switching between both modes does not leave a copy behind. A fragment whose block is no longer found
+// replaces its earlier copy in place; if there is none, the fragment is returned as unplaced and left
+// to the caller.
+func InjectPerCRD(
+	base string, fragments []CRDFragment, shared string, targets CRDTargets,
+) (string, []CRDFragment, error) {
+	if targets.Placement == Replace {
+		return "", nil, errors.New("the blocks of the CRDs cannot be replaced")
+	}
+	doc, err := scan(base, targets.Block, targets.Header)
+	if err != nil {
+		return "", nil, err
+	}
+
+	blocks := crdBlocks(doc, doc.matches[0], doc.matches[1])
+
+	var edits []edit
+	var unplaced []CRDFragment
+	inserts := map[*elementSpan]*edit{}
+	var placed []*edit // in the order of the fragments
+	kept := map[string]bool{}
+	for _, f := range fragments {
+		injected := wrap(f.markerArgs(), f.HTML)
+		b := bestBlock(blocks, f)
+		if b == nil {
+			if r := doc.region(f.markerArgs()); r != nil {
+				placed = append(placed, &edit{r.start, r.end, injected})
+				kept[r.args] = true
+			} else {
+				unplaced = append(unplaced, f)
+			}
+			continue
+		}
+		if e, ok := inserts[b]; ok {
+			e.text += injected
+			continue
+		}
+		e, err := placeAt(b, targets.Placement, injected)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", f, err)
+		}
+		inserts[b] = &e
+		placed = append(placed, &e)
+	}
+	if len(placed) > 0 && shared != "" {
+		placed[0].text = wrap(crdSharedArgs, shared) + placed[0].text
+	}
+	for _, e := range placed {
+		edits = append(edits, *e)
+	}
+
+	for _, r := range doc.regions {
+		if r.args == "" || r.args == crdSharedArgs || strings.HasPrefix(r.args, "crd=") && !kept[r.args] {
+			edits = append(edits, edit{r.start, r.end, ""})
+		}
+	}
+	if doc.legacy != nil && doc.region("") == nil {
+		edits = append(edits, edit{doc.legacy.start, doc.legacy.end, ""})
+	}
+	return applyEdits(base, edits), unplaced, nil
+}
+
+// crdBlock is a block CTY renders for a CRD, with the words of its header.
+type crdBlock struct {
+	el    *elementSpan
+	words map[string]bool
+}
+
+// crdBlocks pairs the blocks with the first header inside them, leaving out the blocks without a header
+// and those of a section injected without markers.
+func crdBlocks(doc *scanned, blocks, headers []*elementSpan) []crdBlock {
+	var out []crdBlock
+	for _, b := range blocks {
+		if doc.legacy != nil && doc.legacy.start <= b.start && b.end <= doc.legacy.end {
+			continue
+		}
+		for _, h := range headers {
+			if b.contains(span{h.start, h.end}) {
+				out = append(out, crdBlock{el: b, words: headerWords(h.text)})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// bestBlock returns the block of the CRD of f, nil if no header names its Kind.
+func bestBlock(blocks []crdBlock, f CRDFragment) *elementSpan {
+	var best *elementSpan
+	bestScore := 0
+	for _, b := range blocks {
+		if !b.words[f.Kind] {
+			continue
+		}
+		score := 1
+		if f.Group != "" && (b.words[f.Group] || b.words[f.Group+"/"+f.Version]) {
+			score++
+		}
+		if f.Version != "" && (b.words[f.Version] || b.words[f.Group+"/"+f.Version]) {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = b.el, score
+		}
+	}
+	return best
+}
+
+// headerWords splits the text of a header into words, keeping the dots, dashes and slashes of API
+// groups and versions, e.g. "zeebe.camunda.io/v1alpha1" yields itself, "zeebe.camunda.io" and
+// "v1alpha1".
+func headerWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("./-", r)
+	}) {
+		w = strings.Trim(w, ".-/")
+		if w == "" {
+			continue
+		}
+		words[w] = true
+		for _, part := range strings.Split(w, "/") {
+			words[part] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,204 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func crdTargets(t *testing.T) CRDTargets {
+	t.Helper()
+	block, err := ParseSelector(".card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := ParseSelector(".card-header")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return CRDTargets{Block: block, Header: header, Placement: Append}
+}
+
+func card(header string) string {
+	return `<div class="card"><div class="card-header">` + header + `</div><div class="card-body">schema</div></div>`
+}
+
+// injectWithFallback injects like -per-crd does: every fragment into its card, the rest as one section
+// at div.content.
+func injectWithFallback(t *testing.T, base string, fragments []CRDFragment) string {
+	t.Helper()
+	out, unplaced, err := InjectPerCRD(base, fragments, "", crdTargets(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unplaced) == 0 {
+		return out
+	}
+	var names []string
+	for _, f := range unplaced {
+		names = append(names, f.Kind)
+	}
+	sel, err := ParseSelector("div.content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback := Target{Selector: sel, Placement: Append, KeepPerCRD: true}
+	out, err = Inject(out, "fallback:"+strings.Join(names, ","), fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestInjectPerCRD(t *testing.T) {
+	cluster := CRDFragment{Kind: "Cluster", Group: "example.com", Version: "v1", HTML: "cluster-conditions"}
+	clusterV2 := CRDFragment{Kind: "Cluster", Group: "example.com", Version: "v2", HTML: "cluster-v2-conditions"}
+	gateway := CRDFragment{Kind: "Gateway", Group: "example.com", Version: "v1", HTML: "gateway-conditions"}
+
+	tests := []struct {
+		name      string
+		base      string
+		fragments []CRDFragment
+		want      string
+	}{
+		{
+			name:      "every CRD has a card",
+			base:      `<div class="content">` + card("Gateway example.com/v1") + card("Cluster example.com/v1") + `</div>`,
+			fragments: []CRDFragment{cluster, gateway},
+			want: `<div class="content">` +
+				`<div class="card"><div class="card-header">Gateway example.com/v1</div><div class="card-body">schema</div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Gateway -->gateway-conditions<!-- cty-conditions:end --></div>` +
+				`<div class="card"><div class="card-header">Cluster example.com/v1</div><div class="card-body">schema</div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->cluster-conditions<!-- cty-conditions:end --></div>` +
+				`</div>`,
+		},
+		{
+			name:      "CRDs without a card go to the fallback section",
+			base:      `<div class="content">` + card("Cluster example.com/v1") + `</div>`,
+			fragments: []CRDFragment{cluster, gateway},
+			want: `<div class="content">` +
+				`<div class="card"><div class="card-header">Cluster example.com/v1</div><div class="card-body">schema</div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->cluster-conditions<!-- cty-conditions:end --></div>` +
+				`<!-- cty-conditions:begin -->fallback:Gateway<!-- cty-conditions:end -->` +
+				`</div>`,
+		},
+		{
+			name:      "the card naming the version wins",
+			base:      `<div class="content">` + card("Cluster v1") + card("Cluster v2") + `</div>`,
+			fragments: []CRDFragment{clusterV2},
+			want: `<div class="content">` + card("Cluster v1") +
+				`<div class="card"><div class="card-header">Cluster v2</div><div class="card-body">schema</div>` +
+				`<!-- cty-conditions:begin crd=example.com/v2/Cluster -->cluster-v2-conditions<!-- cty-conditions:end --></div>` +
+				`</div>`,
+		},
+		{
+			name: "a combined section is replaced",
+			base: `<div class="content">` + card("Cluster") +
+				`<!-- cty-conditions:begin -->old<!-- cty-conditions:end --></div>`,
+			fragments: []CRDFragment{cluster},
+			want: `<div class="content">` +
+				`<div class="card"><div class="card-header">Cluster</div><div class="card-body">schema</div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->cluster-conditions<!-- cty-conditions:end --></div>` +
+				`</div>`,
+		},
+		{
+			name: "a fragment whose card is gone is updated in place",
+			base: `<div class="content"><div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->old<!-- cty-conditions:end --></div></div>`,
+			fragments: []CRDFragment{cluster},
+			want: `<div class="content"><div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->cluster-conditions<!-- cty-conditions:end --></div></div>`,
+		},
+		{
+			name: "fragments of CRDs no longer documented are removed",
+			base: `<div class="content">` + card("Cluster") +
+				`<!-- cty-conditions:begin crd=example.com/v1/Gone -->old<!-- cty-conditions:end --></div>`,
+			fragments: []CRDFragment{cluster},
+			want: `<div class="content">` +
+				`<div class="card"><div class="card-header">Cluster</div><div class="card-body">schema</div>` +
+				`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->cluster-conditions<!-- cty-conditions:end --></div>` +
+				`</div>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := injectWithFallback(t, tt.base, tt.fragments)
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if again := injectWithFallback(t, got, tt.fragments); again != got {
+				t.Fatalf("not idempotent, second run gave\n%s", again)
+			}
+		})
+	}
+}
+
+func TestInjectRemovesPerCRDFragments(t *testing.T) {
+	base := `<div class="content">` + card("Cluster") +
+		`<!-- cty-conditions:begin crds -->styles<!-- cty-conditions:end -->` +
+		`<!-- cty-conditions:begin crd=example.com/v1/Cluster -->old<!-- cty-conditions:end --></div>`
+	sel, err := ParseSelector("div.content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Inject(base, "section", Target{Selector: sel, Placement: Append})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<div class="content">` + card("Cluster") +
+		`<!-- cty-conditions:begin -->section<!-- cty-conditions:end --></div>`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInjectPerCRDShared(t *testing.T) {
+	cluster := CRDFragment{Kind: "Cluster", HTML: "cluster-conditions"}
+	gateway := CRDFragment{Kind: "Gateway", HTML: "gateway-conditions"}
+	base := `<div class="content">` + card("Gateway") + card("Cluster") + `</div>`
+	const styles = `<!-- cty-conditions:begin crds -->styles<!-- cty-conditions:end -->`
+
+	inject := func(base string, fragments ...CRDFragment) string {
+		t.Helper()
+		out, unplaced, err := InjectPerCRD(base, fragments, "styles", crdTargets(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unplaced) > 0 {
+			t.Fatalf("unplaced %v", unplaced)
+		}
+		return out
+	}
+
+	got := inject(base, cluster, gateway)
+	if n := strings.Count(got, "styles"); n != 1 {
+		t.Errorf("%d copies of the shared styles, want 1:\n%s", n, got)
+	}
+	// in front of the first fragment placed, in the card of Cluster
+	want := `<div class="content">` +
+		`<div class="card"><div class="card-header">Gateway</div><div class="card-body">schema</div>` +
+		`<!-- cty-conditions:begin crd=Gateway -->gateway-conditions<!-- cty-conditions:end --></div>` +
+		`<div class="card"><div class="card-header">Cluster</div><div class="card-body">schema</div>` +
+		styles + `<!-- cty-conditions:begin crd=Cluster -->cluster-conditions<!-- cty-conditions:end --></div>` +
+		`</div>`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if again := inject(got, cluster, gateway); again != got {
+		t.Errorf("injecting again changed the page:\n%s\nwant\n%s", again, got)
+	}
+
+	// the styles move along with the first fragment placed
+	got = inject(got, gateway)
+	want = `<div class="content">` +
+		`<div class="card"><div class="card-header">Gateway</div><div class="card-body">schema</div>` +
+		styles + `<!-- cty-conditions:begin crd=Gateway -->gateway-conditions<!-- cty-conditions:end --></div>` +
+		card("Cluster") + `</div>`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// and are removed with the last one
+	if got = inject(got); got != base {
+		t.Errorf("got\n%s\nwant\n%s", got, base)
+	}
+}
@@ -0,0 +1,150 @@
+package html
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PropertyTargets selects a property of the schema CTY renders for a CRD.
+type PropertyTargets struct {
+	Property *Selector // the node of a property, nested in the node of its parent property
+	Name     *Selector // the element naming a property, the first one inside its node
+	Path     []string  // the names of the property and its parents, e.g. status, conditions
+}
+
+// propertyArgs are the arguments of the begin marker of a fragment injected into a property.
+const propertyArgs = "property"
+
+// InjectIntoProperty appends every fragment to the property node at props.Path inside the block of its
+// CRD, see InjectPerCRD for how the block is found. The path matches a property if it ends with the
+// names of the property and its parents, so it does not need to start at the root of the schema.
+//
+// shared, e.g. the styles and scripts the fragments rely on, is injected once, in front of the first
+// fragment placed.
+//
+// Fragments injected into properties by an earlier run are removed first, also those of CRDs no longer
+// given, so passing no fragments removes them all. The fragments whose block or property is not found
+// are returned as unplaced.
+func InjectIntoProperty(
+	base string, fragments []CRDFragment, shared string, blocks CRDTargets, props PropertyTargets,
+) (string, []CRDFragment, error) {
+	doc, err := scan(base, blocks.Block, blocks.Header, props.Property, props.Name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var edits []edit
+	for _, r := range doc.regions {
+		if isProperty(r.args) {
+			edits = append(edits, edit{r.start, r.end, ""})
+		}
+	}
+
+	candidates := crdBlocks(doc, doc.matches[0], doc.matches[1])
+	properties, names := doc.matches[2], doc.matches[3]
+	var unplaced []CRDFragment
+	inserts := map[*elementSpan]*edit{}
+	var order []*elementSpan
+	for _, f := range fragments {
+		injected := wrap(propertyArgs+" "+f.markerArgs(), f.HTML)
+		b := bestBlock(candidates, f)
+		var prop *elementSpan
+		if b != nil {
+			prop = findProperty(b, properties, names, props.Path)
+		}
+		if prop == nil {
+			unplaced = append(unplaced, f)
+			continue
+		}
+		if e, ok := inserts[prop]; ok {
+			e.text += injected
+			continue
+		}
+		inserts[prop] = &edit{prop.closeStart, prop.closeStart, injected}
+		order = append(order, prop)
+	}
+	if len(order) > 0 && shared != "" {
+		first := inserts[order[0]]
+		first.text = wrap(propertyArgs, shared) + first.text
+	}
+	for _, p := range order {
+		edits = append(edits, *inserts[p])
+	}
+	return applyEdits(base, edits), unplaced, nil
+}
+
+// InjectedIntoProperties returns true if doc holds fragments injected by InjectIntoProperty.
+func InjectedIntoProperties(doc string) (bool, error) {
+	scanned, err := scan(doc)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(scanned.regions, func(r region) bool { return isProperty(r.args) }), nil
+}
+
+// isProperty returns true for the arguments of the begin markers InjectIntoProperty writes.
+func isProperty(args string) bool {
+	return args == propertyArgs || strings.HasPrefix(args, propertyArgs+" ")
+}
+
+// IDs returns the ids of the elements of doc.
+func IDs(doc string) map[string]bool {
+	ids := map[string]bool{}
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ids
+		case html.StartTagToken, html.SelfClosingTagToken:
+			for _, a := range z.Token().Attr {
+				if a.Key == "id" {
+					ids[a.Val] = true
+				}
+			}
+		}
+	}
+}
+
+// findProperty returns the first property node inside block whose path ends with path, nil if there is
+// none. properties and names are in document order.
+func findProperty(block *elementSpan, properties, names []*elementSpan, path []string) *elementSpan {
+	name := func(p *elementSpan) string {
+		for _, n := range names {
+			if p.contains(span{n.start, n.end}) {
+				if f := strings.Fields(n.text); len(f) > 0 {
+					return f[0]
+				}
+				return ""
+			}
+		}
+		return ""
+	}
+	for _, p := range properties {
+		if !block.contains(span{p.start, p.end}) {
+			continue
+		}
+		// the names from the root of the schema down to p
+		var chain []string
+		for _, parent := range properties {
+			if parent.start < p.start && parent.contains(span{p.start, p.end}) {
+				chain = append(chain, name(parent))
+			}
+		}
+		chain = append(chain, name(p))
+		if len(chain) >= len(path) && equalFold(chain[len(chain)-len(path):], path) {
+			return p
+		}
+	}
+	return nil
+}
+
+func equalFold(a, b []string) bool {
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
@@ -0,0 +1,93 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func property(name, content string) string {
+	return `<div class="prop"><span class="name">` + name + `</span>` + content + `</div>`
+}
+
+func TestInjectIntoProperty(t *testing.T) {
+	propSel, err := ParseSelector(".prop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nameSel, err := ParseSelector(".name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := PropertyTargets{Property: propSel, Name: nameSel, Path: []string{"status", "conditions"}}
+
+	schema := property("spec", property("conditions", "")) +
+		property("status", property("phase", "")+property("conditions", ""))
+	base := `<div class="content">` +
+		`<div class="card"><div class="card-header">Cluster</div>` + schema + `</div>` +
+		`<div class="card"><div class="card-header">Gateway</div>` + schema + `</div>` +
+		`<div class="card"><div class="card-header">Other</div></div>` +
+		`</div>`
+	fragments := []CRDFragment{
+		{Kind: "Cluster", HTML: "cluster-types"},
+		{Kind: "Gateway", HTML: "gateway-types"},
+		{Kind: "Other", HTML: "other-types"},
+	}
+
+	got, unplaced, err := InjectIntoProperty(base, fragments, "shared", crdTargets(t), props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unplaced) != 1 || unplaced[0].Kind != "Other" {
+		t.Errorf("unplaced = %v, want Other", unplaced)
+	}
+	list := func(kind, html string) string {
+		return property("status", property("phase", "")+property("conditions",
+			`<!-- cty-conditions:begin property crd=`+kind+` -->`+html+`<!-- cty-conditions:end -->`))
+	}
+	want := `<div class="content">` +
+		`<div class="card"><div class="card-header">Cluster</div>` + property("spec", property("conditions", "")) +
+		strings.Replace(list("Cluster", "cluster-types"), `<!-- cty-conditions:begin property crd`,
+			`<!-- cty-conditions:begin property -->shared<!-- cty-conditions:end --><!-- cty-conditions:begin property crd`, 1) +
+		`</div>` +
+		`<div class="card"><div class="card-header">Gateway</div>` + property("spec", property("conditions", "")) +
+		list("Gateway", "gateway-types") + `</div>` +
+		`<div class="card"><div class="card-header">Other</div></div>` +
+		`</div>`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	again, _, err := InjectIntoProperty(got, fragments, "shared", crdTargets(t), props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != got {
+		t.Fatalf("not idempotent, second run gave\n%s", again)
+	}
+
+	removed, _, err := InjectIntoProperty(got, nil, "shared", crdTargets(t), props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != base {
+		t.Fatalf("injecting no fragments gave\n%s\nwant\n%s", removed, base)
+	}
+
+	for doc, want := range map[string]bool{
+		got:  true,
+		base: false,
+		`<!-- cty-conditions:begin crd=Cluster -->conditions<!-- cty-conditions:end -->`: false,
+		`<!-- cty-conditions:begin property -->shared<!-- cty-conditions:end -->`:        true,
+	} {
+		if listed, err := InjectedIntoProperties(doc); err != nil || listed != want {
+			t.Errorf("InjectedIntoProperties(%s) = %v, %v, want %v", doc, listed, err, want)
+		}
+	}
+}
+
+func TestIDs(t *testing.T) {
+	ids := IDs(`<div id="a"><img id="b"/><p>id="c"</p></div>`)
+	if len(ids) != 2 || !ids["a"] || !ids["b"] {
+		t.Fatalf("IDs = %v, want a and b", ids)
+	}
+}
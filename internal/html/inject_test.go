@@ -0,0 +1,157 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func target(t *testing.T, selector string, placement Placement) Target {
+	t.Helper()
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Target{Selector: sel, Placement: placement}
+}
+
+func TestInject(t *testing.T) {
+	const page = `<div class="content"><h2>Schema</h2><p>text</p></div>`
+	tests := []struct {
+		name    string
+		base    string
+		target  Target
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "append",
+			base:   page,
+			target: target(t, "div.content", Append),
+			want: `<div class="content"><h2>Schema</h2><p>text</p>` +
+				`<!-- cty-conditions:begin -->new<!-- cty-conditions:end --></div>`,
+		},
+		{
+			name:   "prepend",
+			base:   page,
+			target: target(t, "div.content", Prepend),
+			want: `<div class="content"><!-- cty-conditions:begin -->new<!-- cty-conditions:end -->` +
+				`<h2>Schema</h2><p>text</p></div>`,
+		},
+		{
+			name:   "before",
+			base:   page,
+			target: target(t, "h2", Before),
+			want: `<div class="content"><!-- cty-conditions:begin -->new<!-- cty-conditions:end -->` +
+				`<h2>Schema</h2><p>text</p></div>`,
+		},
+		{
+			name:   "after",
+			base:   page,
+			target: target(t, "h2", After),
+			want: `<div class="content"><h2>Schema</h2>` +
+				`<!-- cty-conditions:begin -->new<!-- cty-conditions:end --><p>text</p></div>`,
+		},
+		{
+			name:   "replace",
+			base:   page,
+			target: target(t, "p", Replace),
+			want: `<div class="content"><h2>Schema</h2>` +
+				`<!-- cty-conditions:begin -->new<!-- cty-conditions:end --></div>`,
+		},
+		{
+			name:   "the last match is used",
+			base:   `<p>1</p><p>2</p>`,
+			target: target(t, "p", After),
+			want:   `<p>1</p><p>2</p><!-- cty-conditions:begin -->new<!-- cty-conditions:end -->`,
+		},
+		{
+			name:   "unless First is set",
+			base:   `<p>1</p><p>2</p>`,
+			target: Target{Selector: target(t, "p", After).Selector, Placement: After, First: true},
+			want:   `<p>1</p><!-- cty-conditions:begin -->new<!-- cty-conditions:end --><p>2</p>`,
+		},
+		{
+			name:   "the rest of the document is kept byte for byte",
+			base:   "<!DOCTYPE html>\n<DIV class=content data-x='a&amp;b'>&nbsp;<br>\n<P>unclosed</DIV>\n",
+			target: target(t, "div.content", Append),
+			want: "<!DOCTYPE html>\n<DIV class=content data-x='a&amp;b'>&nbsp;<br>\n<P>unclosed" +
+				"<!-- cty-conditions:begin -->new<!-- cty-conditions:end --></DIV>\n",
+		},
+		{
+			name: "an earlier section is replaced",
+			base: `<div class="content"><h2>Schema</h2>` +
+				`<!-- cty-conditions:begin -->old<!-- cty-conditions:end --><p>text</p></div>`,
+			target: target(t, "div.content", Append),
+			want: `<div class="content"><h2>Schema</h2><p>text</p>` +
+				`<!-- cty-conditions:begin -->new<!-- cty-conditions:end --></div>`,
+		},
+		{
+			name:   "a section that replaced its target is updated in place",
+			base:   `<div class="content"><!-- cty-conditions:begin -->old<!-- cty-conditions:end --></div>`,
+			target: target(t, "p", Replace),
+			want:   `<div class="content"><!-- cty-conditions:begin -->new<!-- cty-conditions:end --></div>`,
+		},
+		{
+			name: "a section injected without markers is replaced",
+			base: `<div class="content"><h2>Schema</h2>` + "\n" +
+				`<style>.x{}</style>` + "\n" +
+				`<div class="card"><div class="accordion" id="conditions-root">old</div></div></div>`,
+			target: target(t, "div.content", Append),
+			want: `<div class="content"><h2>Schema</h2>` + "\n" +
+				`<!-- cty-conditions:begin -->new<!-- cty-conditions:end --></div>`,
+		},
+		{
+			name:    "no match",
+			base:    page,
+			target:  target(t, "table", Append),
+			wantErr: "no element matches table",
+		},
+		{
+			name:    "append to a void element",
+			base:    `<div><img src="x"></div>`,
+			target:  target(t, "img", Append),
+			wantErr: "cannot append to <img>",
+		},
+		{
+			name:    "begin marker without end marker",
+			base:    `<div><!-- cty-conditions:begin -->old</div>`,
+			target:  target(t, "div", Append),
+			wantErr: "without a matching",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inject(tt.base, "new", tt.target)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			again, err := Inject(got, "new", tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Fatalf("not idempotent, second run gave\n%s", again)
+			}
+		})
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	for _, p := range []Placement{Append, Prepend, Before, After, Replace} {
+		if got, err := ParsePlacement(string(p)); err != nil || got != p {
+			t.Errorf("ParsePlacement(%q) = %q, %v", p, got, err)
+		}
+	}
+	if _, err := ParsePlacement("inside"); err == nil {
+		t.Error("ParsePlacement(inside) did not fail")
+	}
+}
@@ -0,0 +1,88 @@
+package renderers
+
+import (
+	"html/template"
+
+	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+// allowedTypesAssetsTemplate holds what the lists of all CRDs share, it is injected once per page.
+// ctyReveal expands the collapsed accordions around a condition, so that following a link shows it.
+const allowedTypesAssetsTemplate = `
+<style>
+  .cty-allowed-types { display: flex; flex-wrap: wrap; align-items: baseline; gap: 0.5rem; margin: 0.5rem 0 1rem; font-size: 0.85rem; }
+  .cty-allowed-types-title { font-weight: 600; text-transform: uppercase; font-size: 0.75rem; opacity: 0.7; }
+</style>
+<script>
+  function ctyReveal(id) {
+    var el = document.getElementById(id);
+    for (var p = el && el.parentElement; p; p = p.parentElement) {
+      if (p.classList.contains("collapse") && !p.classList.contains("show") && p.previousElementSibling) {
+        toggleAccordion(p.previousElementSibling);
+      }
+    }
+  }
+</script>`
+
+const allowedTypesTemplate = `
+<div class="cty-allowed-types">
+  <span class="cty-allowed-types-title">Allowed types</span>
+  {{ range .Conditions }}{{ if .Linked -}}
+  <a class="property-type" href="#{{ .Anchor }}" onclick="ctyReveal({{ .Anchor }})"{{ if .Title }} title="{{ .Title }}"{{ end }}>{{ .Name }}</a>
+  {{- else -}}
+  <span class="property-type"{{ if .Title }} title="{{ .Title }}"{{ end }}>{{ .Name }}</span>
+  {{- end }}
+  {{ else }}<span class="muted">No conditions documented for this resource.</span>{{ end }}
+</div>`
+
+// AllowedTypesAssetsNode renders the styles and script the AllowedTypesNodes of a page rely on.
+type AllowedTypesAssetsNode struct {
+	hr.BaseHTMLGenerator
+}
+
+func NewAllowedTypesAssetsNode() *AllowedTypesAssetsNode {
+	return &AllowedTypesAssetsNode{
+		BaseHTMLGenerator: hr.BaseHTMLGenerator{
+			Template: hr.MustParseTemplate("allowedTypesAssets", allowedTypesAssetsTemplate),
+		},
+	}
+}
+
+func (n *AllowedTypesAssetsNode) Generate() (template.HTML, error) {
+	return n.ExecTemplate("", nil)
+}
+
+// AllowedTypesNode renders the names of the documented conditions of a CRD for the conditions property
+// of the CRD's schema. A name links to the entry of the condition if Linked reports that the page has it.
+type AllowedTypesNode struct {
+	hr.BaseHTMLGenerator
+
+	CRD    model.CRD
+	Linked func(anchor string) bool
+}
+
+func NewAllowedTypesNode(crd model.CRD, linked func(anchor string) bool) *AllowedTypesNode {
+	return &AllowedTypesNode{
+		BaseHTMLGenerator: hr.BaseHTMLGenerator{
+			Template: hr.MustParseTemplate("allowedTypes", allowedTypesTemplate),
+		},
+		CRD:    crd,
+		Linked: linked,
+	}
+}
+
+func (n *AllowedTypesNode) Generate() (template.HTML, error) {
+	type link struct {
+		Name   string
+		Title  string
+		Anchor string
+		Linked bool
+	}
+	conditions := make([]link, len(n.CRD.Conditions))
+	for i, c := range n.CRD.Conditions {
+		anchor := n.CRD.ConditionAnchor(c.Name)
+		conditions[i] = link{Name: c.Name, Title: c.DisplayName, Anchor: anchor, Linked: n.Linked(anchor)}
+	}
+	return n.ExecTemplate("", map[string]any{"Conditions": conditions})
+}
@@ -4,39 +4,52 @@ import (
 	"html/template"
 
 	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
 )
 
 const reasonTemplate = `
 <div class="accordion-item-static">
   <div class="property-info">
     <span class="property-name">{{ .Name }}</span>
+    {{ if .DisplayName }}<span class="cty-display-name">{{ .DisplayName }}</span>{{ end }}
 	<span class="property-type property-required">Reason Type</span>
     <span class="property-type">string</span>
+    {{ severityBadge .Severity }}
+    {{ if .Shared }}<span class="property-type cty-badge cty-shared" title="Shared by several conditions">Shared</span>{{ end }}
+    {{ if eq .Provenance.Origin "catalog" }}<span class="property-type cty-badge cty-catalog" title="Documented in {{ .Provenance.File }}">Catalog</span>{{ end }}
   </div>
   {{ if .Description }}<div class="property-description">{{ formatComment .Description }}</div>{{ end }}
+  {{ .GoAPI }}
 </div>`
 
 type ReasonNode struct {
 	hr.BaseHTMLGenerator
 
-	Name        string
-	Description string
+	Reason model.ReasonDoc
 }
 
-func NewReasonNode(name, description string) *ReasonNode {
+func NewReasonNode(reason model.ReasonDoc) *ReasonNode {
 	return &ReasonNode{
 		BaseHTMLGenerator: hr.BaseHTMLGenerator{
 			Template: hr.MustParseTemplate("reason", reasonTemplate),
 		},
-		Name:        name,
-		Description: description,
+		Reason: reason,
 	}
 }
 
 func (n *ReasonNode) Generate() (template.HTML, error) {
+	goAPI, err := NewGoAPINode(n.Reason.Decl).Generate()
+	if err != nil {
+		return "", err
+	}
 	data := map[string]any{
-		"Name":        n.Name,
-		"Description": n.Description,
+		"Name":        n.Reason.Name,
+		"DisplayName": n.Reason.DisplayName,
+		"Severity":    n.Reason.Severity,
+		"Shared":      n.Reason.Shared,
+		"Description": n.Reason.Description,
+		"Provenance":  n.Reason.Provenance,
+		"GoAPI":       goAPI,
 	}
 	return n.ExecTemplate("", data)
 }
@@ -5,22 +5,28 @@ import (
 	"strings"
 
 	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
 )
 
 const conditionTemplate = `
-<div class="accordion-item">
+<div class="accordion-item" id="{{ .Anchor }}">
   <button class="accordion-button collapsed" type="button" onclick="toggleAccordion(this)">
     <div style="width: 100%;">
       <div class="property-info">
         <span class="property-name">{{ .Name }}</span>
+        {{ if .DisplayName }}<span class="cty-display-name">{{ .DisplayName }}</span>{{ end }}
         <span class="property-type property-required">Condition Type</span>
-		<span class="property-type">string</span>
+		<span class="property-type">{{ .Type }}</span>
+        {{ polarityBadge .Polarity }}
+        {{ severityBadge .Severity }}
+        {{ if eq .Provenance.Origin "catalog" }}<span class="property-type cty-badge cty-catalog" title="Documented in {{ .Provenance.File }}">Catalog</span>{{ end }}
       </div>
       {{ if .Description }}<div class="property-description">{{ formatComment .Description }}</div>{{ end }}
     </div>
   </button>
   <div class="collapse">
     <div class="accordion-body">
+      {{ .GoAPI }}
       <h4 class="d-flex align-items-center gap-2 mb-4">
         Reasons
       </h4>
@@ -38,17 +44,17 @@ const conditionTemplate = `
 type ConditionNode struct {
 	hr.BaseHTMLGenerator
 
-	Name        string
-	Description string
+	CRD       model.CRD
+	Condition model.ConditionDoc
 }
 
-func NewConditionNode(name, description string) *ConditionNode {
+func NewConditionNode(crd model.CRD, condition model.ConditionDoc) *ConditionNode {
 	return &ConditionNode{
 		BaseHTMLGenerator: hr.BaseHTMLGenerator{
 			Template: hr.MustParseTemplate("condition", conditionTemplate),
 		},
-		Name:        name,
-		Description: description,
+		CRD:       crd,
+		Condition: condition,
 	}
 }
 
@@ -57,10 +63,21 @@ func (n *ConditionNode) Generate() (template.HTML, error) {
 	if err != nil {
 		return "", err
 	}
-	safeID := strings.ToLower(strings.ReplaceAll(n.Name, " ", "-"))
+	goAPI, err := NewGoAPINode(n.Condition.Decl).Generate()
+	if err != nil {
+		return "", err
+	}
+	safeID := strings.ToLower(strings.ReplaceAll(n.Condition.Name, " ", "-"))
 	data := map[string]any{
-		"Name":        n.Name,
-		"Description": n.Description,
+		"Name":        n.Condition.Name,
+		"Anchor":      n.CRD.ConditionAnchor(n.Condition.Name),
+		"DisplayName": n.Condition.DisplayName,
+		"Type":        n.Condition.Type,
+		"Polarity":    n.Condition.Polarity,
+		"Severity":    n.Condition.Severity,
+		"Description": n.Condition.Description,
+		"Provenance":  n.Condition.Provenance,
+		"GoAPI":       goAPI,
 		"SafeID":      safeID,
 		"HasChildren": len(parts) > 0,
 		"Children":    template.HTML(strings.Join(parts, "")),
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
 )
 
 const crdTemplate = `
@@ -14,8 +15,9 @@ const crdTemplate = `
       <div class="property-info">
         <span class="property-name">{{ .Name }}</span>
         <span class="property-type property-required">Condition Options</span>
+        {{ if .GroupVersion }}<span class="property-type">{{ .GroupVersion }}</span>{{ end }}
       </div>
-      <div class="property-description">Condition types for the {{ .Name }} resource.</div>
+      <div class="property-description">Condition types for the {{ .Name }} resource{{ if .Version }} in version {{ .Version }}{{ end }}.</div>
     </div>
   </button>
   <div class="collapse">
@@ -30,15 +32,15 @@ const crdTemplate = `
 type CRDNode struct {
 	hr.BaseHTMLGenerator
 
-	Name string
+	CRD model.CRD
 }
 
-func NewCRDNode(name string) *CRDNode {
+func NewCRDNode(crd model.CRD) *CRDNode {
 	return &CRDNode{
 		BaseHTMLGenerator: hr.BaseHTMLGenerator{
 			Template: hr.MustParseTemplate("crd", crdTemplate),
 		},
-		Name: name,
+		CRD: crd,
 	}
 }
 
@@ -47,12 +49,17 @@ func (n *CRDNode) Generate() (template.HTML, error) {
 	if err != nil {
 		return "", err
 	}
-	safeID := strings.ToLower(strings.ReplaceAll(n.Name, " ", "-"))
+	safeID := strings.ToLower(strings.ReplaceAll(n.CRD.Name, " ", "-"))
+	if gv := n.CRD.GroupVersion(); gv != "" {
+		safeID += "-" + strings.ToLower(strings.NewReplacer("/", "-", ".", "-").Replace(gv))
+	}
 	data := map[string]any{
-		"Name":        n.Name,
-		"HasChildren": len(parts) > 0,
-		"SafeID":      safeID,
-		"Children":    template.HTML(strings.Join(parts, "")),
+		"Name":         n.CRD.Name,
+		"GroupVersion": n.CRD.GroupVersion(),
+		"Version":      n.CRD.Version,
+		"HasChildren":  len(parts) > 0,
+		"SafeID":       safeID,
+		"Children":     template.HTML(strings.Join(parts, "")),
 	}
 	return n.ExecTemplate("", data)
 }
@@ -0,0 +1,79 @@
+package renderers
+
+import (
+	"html/template"
+	"strings"
+
+	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+// crdSectionAssetsTemplate holds what the sections of all CRDs share, it is injected once per page.
+const crdSectionAssetsTemplate = styleTemplate + `
+<style>
+  .cty-crd-conditions { padding: 1rem 1.25rem; }
+  .cty-crd-conditions-title { display: flex; align-items: center; gap: 0.5rem; margin-bottom: 0.75rem; }
+</style>`
+
+const crdSectionTemplate = `
+<div class="cty-crd-conditions" id="{{ .Anchor }}">
+  <div class="cty-crd-conditions-title">
+    <span class="icon icon-cog"></span>
+    <div>
+      <strong>Conditions</strong>
+      <div style="font-size: 0.9rem; opacity: 0.8;">Condition types &amp; reasons of {{ .Name }}{{ if .GroupVersion }} ({{ .GroupVersion }}){{ end }}</div>
+    </div>
+  </div>
+  <div class="accordion">
+    {{ if .HasChildren }}{{ .Children }}{{ else }}<p class="muted">No conditions documented for this resource.</p>{{ end }}
+  </div>
+</div>`
+
+// CRDSectionAssetsNode renders the styles the CRDSectionNodes of a page rely on.
+type CRDSectionAssetsNode struct {
+	hr.BaseHTMLGenerator
+}
+
+func NewCRDSectionAssetsNode() *CRDSectionAssetsNode {
+	return &CRDSectionAssetsNode{
+		BaseHTMLGenerator: hr.BaseHTMLGenerator{
+			Template: hr.MustParseTemplate("crd-section-assets", crdSectionAssetsTemplate),
+		},
+	}
+}
+
+func (n *CRDSectionAssetsNode) Generate() (template.HTML, error) {
+	return n.ExecTemplate("", nil)
+}
+
+// CRDSectionNode renders the conditions of a single CRD, to be injected into the block CTY renders for
+// that CRD.
+type CRDSectionNode struct {
+	hr.BaseHTMLGenerator
+
+	CRD model.CRD
+}
+
+func NewCRDSectionNode(crd model.CRD) *CRDSectionNode {
+	return &CRDSectionNode{
+		BaseHTMLGenerator: hr.BaseHTMLGenerator{
+			Template: hr.MustParseTemplate("crd-section", crdSectionTemplate),
+		},
+		CRD: crd,
+	}
+}
+
+func (n *CRDSectionNode) Generate() (template.HTML, error) {
+	parts, err := n.RenderChildren()
+	if err != nil {
+		return "", err
+	}
+	data := map[string]any{
+		"Name":         n.CRD.Name,
+		"GroupVersion": n.CRD.GroupVersion(),
+		"Anchor":       n.CRD.Anchor(),
+		"HasChildren":  len(parts) > 0,
+		"Children":     template.HTML(strings.Join(parts, "")),
+	}
+	return n.ExecTemplate("", data)
+}
@@ -0,0 +1,42 @@
+package renderers
+
+import (
+	"html/template"
+
+	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+const goAPITemplate = `
+{{ if .Ident }}
+<div class="cty-go-api">
+  <span class="cty-go-api-title">Go API</span>
+  <code title="{{ .ImportPath }}">{{ .Ident }}</code>
+  {{ if .TypeName }}<span>{{ if eq .Kind "field" }}field of{{ else }}of type{{ end }} <code>{{ .TypeName }}</code></span>{{ end }}
+  {{ if .URL -}}
+  <a class="cty-go-api-source" href="{{ .URL }}" target="_blank" rel="noopener">{{ .File }}:{{ .Line }}</a>
+  {{- else if .File -}}
+  <span class="cty-go-api-source">{{ .File }}:{{ .Line }}</span>
+  {{- end }}
+</div>
+{{ end }}`
+
+// GoAPINode renders the Go declaration a condition or reason is documented on, nothing if it has none.
+type GoAPINode struct {
+	hr.BaseHTMLGenerator
+
+	Decl model.GoDecl
+}
+
+func NewGoAPINode(decl model.GoDecl) *GoAPINode {
+	return &GoAPINode{
+		BaseHTMLGenerator: hr.BaseHTMLGenerator{
+			Template: hr.MustParseTemplate("goAPI", goAPITemplate),
+		},
+		Decl: decl,
+	}
+}
+
+func (n *GoAPINode) Generate() (template.HTML, error) {
+	return n.ExecTemplate("", n.Decl)
+}
@@ -0,0 +1,96 @@
+package renderers
+
+import (
+	"html/template"
+	"strings"
+
+	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
+)
+
+// The page reproduces the parts of the CTY look and its toggleAccordion script that the section relies
+// on, so it can be published without running CTY. Its content is a <div class="content"> like in CTY.
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <style>
+    :root { --cty-border: #d0d7de; --cty-muted: #57606a; --cty-accent: #0969da; --cty-bg-subtle: #f6f8fa; }
+    * { box-sizing: border-box; }
+    body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 1rem; line-height: 1.5; color: #1f2328; background: #fff; }
+    code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.85em; padding: 0.1em 0.3em; border-radius: 4px; background: var(--cty-bg-subtle); }
+    a { color: var(--cty-accent); }
+    .content { max-width: 1100px; margin: 0 auto; padding: 2rem 1rem; }
+    .card { border: 1px solid var(--cty-border); border-radius: 8px; background: #fff; }
+    .card-header { display: flex; align-items: center; gap: 0.75rem; padding: 1rem 1.25rem; border-bottom: 1px solid var(--cty-border); background: var(--cty-bg-subtle); border-radius: 8px 8px 0 0; }
+    .card-body { padding: 1rem 1.25rem; }
+    .icon { display: inline-block; width: 1.25em; text-align: center; font-style: normal; }
+    .icon-cog::before { content: "\2699"; }
+    .icon-info::before { content: "\2139"; color: var(--cty-accent); }
+    .accordion { display: flex; flex-direction: column; gap: 0.5rem; }
+    .accordion-item, .accordion-item-static { border: 1px solid var(--cty-border); border-radius: 6px; }
+    .accordion-item-static { padding: 0.75rem 1rem; }
+    .accordion-button { display: flex; align-items: flex-start; gap: 0.5rem; width: 100%; padding: 0.75rem 1rem; border: 0; background: none; font: inherit; color: inherit; text-align: left; cursor: pointer; }
+    .accordion-button::before { content: "\25BE"; transition: transform 0.15s; }
+    .accordion-button.collapsed::before { transform: rotate(-90deg); }
+    .accordion-button:hover { background: var(--cty-bg-subtle); }
+    .collapse:not(.show) { display: none; }
+    .accordion-body { padding: 0.5rem 1rem 1rem; border-top: 1px solid var(--cty-border); }
+    .property-info { display: flex; flex-wrap: wrap; align-items: baseline; gap: 0.5rem; }
+    .property-name { font-weight: 600; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+    .property-type { font-size: 0.8rem; color: var(--cty-muted); }
+    .property-required { color: var(--cty-accent); }
+    .property-description { margin-top: 0.25rem; color: var(--cty-muted); }
+    .property-description p { margin: 0.25rem 0; }
+    .property-description ul { margin: 0.25rem 0; padding-left: 1.25rem; }
+    .muted { color: var(--cty-muted); font-style: italic; }
+    .d-flex { display: flex; }
+    .align-items-center { align-items: center; }
+    .gap-2 { gap: 0.5rem; }
+    .mb-4 { margin-bottom: 1rem; }
+  </style>
+</head>
+<body>
+  <div class="content">
+    {{ .Children }}
+  </div>
+  <script>
+    function toggleAccordion(button) {
+      button.classList.toggle("collapsed");
+      var collapse = button.nextElementSibling;
+      if (collapse) {
+        collapse.classList.toggle("show");
+      }
+    }
+  </script>
+</body>
+</html>
+`
+
+// PageNode wraps its children, usually a SectionNode, in a self-contained HTML document.
+type PageNode struct {
+	hr.BaseHTMLGenerator
+	Title string
+}
+
+func NewPageNode(title string) *PageNode {
+	return &PageNode{
+		BaseHTMLGenerator: hr.BaseHTMLGenerator{
+			Template: hr.MustParseTemplate("page", pageTemplate),
+		},
+		Title: title,
+	}
+}
+
+func (n *PageNode) Generate() (template.HTML, error) {
+	parts, err := n.RenderChildren()
+	if err != nil {
+		return "", err
+	}
+	data := map[string]any{
+		"Title":    n.Title,
+		"Children": template.HTML(strings.Join(parts, "")),
+	}
+	return n.ExecTemplate("", data)
+}
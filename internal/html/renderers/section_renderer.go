@@ -7,7 +7,23 @@ import (
 	hr "github.com/sourcehawk/cty-generator-addons/internal/html"
 )
 
-const sectionTemplate = `
+// styleTemplate styles the classes the condition and reason nodes add to the CTY ones.
+const styleTemplate = `
+<style>
+  .cty-display-name { font-style: italic; opacity: 0.8; }
+  .cty-badge { font-weight: 600; }
+  .cty-polarity-positive { color: #1a7f37; }
+  .cty-polarity-negative { color: #cf222e; }
+  .cty-severity-info { color: #0969da; }
+  .cty-severity-warning { color: #9a6700; }
+  .cty-severity-error { color: #cf222e; }
+  .cty-shared, .cty-catalog { opacity: 0.7; }
+  .cty-go-api { display: flex; flex-wrap: wrap; align-items: baseline; gap: 0.5rem; margin: 0.5rem 0 1rem; font-size: 0.85rem; }
+  .cty-go-api-title { font-weight: 600; text-transform: uppercase; font-size: 0.75rem; opacity: 0.7; }
+  .cty-go-api-source { margin-left: auto; opacity: 0.8; }
+</style>`
+
+const sectionTemplate = styleTemplate + `
 <div class="card">
   <div class="card-header">
     <span class="icon icon-cog"></span>
@@ -0,0 +1,256 @@
+package html
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector is a parsed CSS selector of the subset injection targets support: compound selectors of a
+// tag name or *, #id, .class, [attr] and [attr=value], combined with descendant (space) and child (>)
+// combinators, e.g. `div.content > h2#conditions`. Several selectors can be separated by commas.
+type Selector struct {
+	source string
+	alts   []complexSelector
+}
+
+// complexSelector is one of the comma separated selectors.
+type complexSelector struct {
+	// compounds are ordered from the outermost to the element itself, combinators[i] joins compounds[i]
+	// and compounds[i+1].
+	compounds   []compound
+	combinators []byte // ' ' or '>'
+}
+
+type compound struct {
+	tag     string // empty matches any tag
+	id      string
+	classes []string
+	attrs   []attrMatcher
+}
+
+type attrMatcher struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// element is an open element while tokenizing, the ancestors a selector is matched against.
+type element struct {
+	tag   string
+	attrs []html.Attribute
+}
+
+// ParseSelector parses a selector of the supported subset, see Selector.
+func ParseSelector(s string) (*Selector, error) {
+	sel := &Selector{source: s}
+	p := &selectorParser{s: strings.TrimSpace(s)}
+	if p.s == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+	for {
+		alt, err := p.complex()
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+		}
+		sel.alts = append(sel.alts, alt)
+		if p.done() {
+			return sel, nil
+		}
+		p.i++ // the ',' complex stopped at
+		p.skipSpace()
+	}
+}
+
+// complex reads a selector up to the end of the input or the next ','.
+func (p *selectorParser) complex() (complexSelector, error) {
+	var sel complexSelector
+	for {
+		c, err := p.compound()
+		if err != nil {
+			return sel, err
+		}
+		sel.compounds = append(sel.compounds, c)
+
+		ws := p.skipSpace()
+		if p.done() || p.peek() == ',' {
+			return sel, nil
+		}
+		switch {
+		case p.peek() == '>':
+			p.i++
+			p.skipSpace()
+			sel.combinators = append(sel.combinators, '>')
+		case ws:
+			sel.combinators = append(sel.combinators, ' ')
+		default:
+			return sel, fmt.Errorf("unsupported %q at offset %d", p.peek(), p.i)
+		}
+	}
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+// matches returns true if the element e, whose open ancestors are ancestors (outermost first), is
+// selected by s.
+func (s *Selector) matches(e element, ancestors []element) bool {
+	for _, alt := range s.alts {
+		last := len(alt.compounds) - 1
+		if alt.compounds[last].matches(e) && alt.matchAncestors(last-1, ancestors) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAncestors returns true if compounds[:i+1] match within ancestors, trying every candidate for
+// descendant combinators.
+func (s complexSelector) matchAncestors(i int, ancestors []element) bool {
+	if i < 0 {
+		return true
+	}
+	if s.combinators[i] == '>' {
+		n := len(ancestors)
+		return n > 0 && s.compounds[i].matches(ancestors[n-1]) && s.matchAncestors(i-1, ancestors[:n-1])
+	}
+	for n := len(ancestors); n > 0; n-- {
+		if s.compounds[i].matches(ancestors[n-1]) && s.matchAncestors(i-1, ancestors[:n-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compound) matches(e element) bool {
+	if c.tag != "" && c.tag != e.tag {
+		return false
+	}
+	if c.id != "" && !hasAttr(e.attrs, "id", c.id) {
+		return false
+	}
+	for _, class := range c.classes {
+		if !hasClass(e.attrs, class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		found := false
+		for _, attr := range e.attrs {
+			if attr.Key == a.key && (!a.hasValue || attr.Val == a.value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type selectorParser struct {
+	s string
+	i int
+}
+
+func (p *selectorParser) done() bool { return p.i >= len(p.s) }
+func (p *selectorParser) peek() byte { return p.s[p.i] }
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.i
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n') {
+		p.i++
+	}
+	return p.i > start
+}
+
+// ident reads a tag, class, id or attribute name.
+func (p *selectorParser) ident() string {
+	start := p.i
+	for !p.done() {
+		c := p.peek()
+		if c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80 {
+			p.i++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.i]
+}
+
+func (p *selectorParser) compound() (compound, error) {
+	var c compound
+	start := p.i
+	switch {
+	case p.done():
+		return c, fmt.Errorf("missing selector after combinator")
+	case p.peek() == '*':
+		p.i++
+	default:
+		c.tag = strings.ToLower(p.ident())
+	}
+	for !p.done() {
+		switch p.peek() {
+		case '#':
+			p.i++
+			if c.id = p.ident(); c.id == "" {
+				return c, fmt.Errorf("missing id after '#'")
+			}
+		case '.':
+			p.i++
+			class := p.ident()
+			if class == "" {
+				return c, fmt.Errorf("missing class after '.'")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			p.i++
+			a, err := p.attr()
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, a)
+		default:
+			if p.i == start {
+				return c, fmt.Errorf("unsupported %q at offset %d", p.peek(), p.i)
+			}
+			return c, nil
+		}
+	}
+	return c, nil
+}
+
+// attr reads an attribute selector after its '[': name], name=value] or name="value"].
+func (p *selectorParser) attr() (attrMatcher, error) {
+	p.skipSpace()
+	a := attrMatcher{key: strings.ToLower(p.ident())}
+	if a.key == "" {
+		return a, fmt.Errorf("missing attribute name after '['")
+	}
+	p.skipSpace()
+	if !p.done() && p.peek() == '=' {
+		p.i++
+		p.skipSpace()
+		a.hasValue = true
+		if !p.done() && (p.peek() == '"' || p.peek() == '\'') {
+			quote := p.peek()
+			end := strings.IndexByte(p.s[p.i+1:], quote)
+			if end < 0 {
+				return a, fmt.Errorf("unterminated attribute value")
+			}
+			a.value = p.s[p.i+1 : p.i+1+end]
+			p.i += end + 2
+		} else {
+			a.value = p.ident()
+		}
+		p.skipSpace()
+	}
+	if p.done() || p.peek() != ']' {
+		return a, fmt.Errorf("expected ']' to close the attribute selector")
+	}
+	p.i++
+	return a, nil
+}
@@ -0,0 +1,79 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	const doc = `<div class="content main" id="top">` +
+		`<section data-kind="crd"><h2 id="a">A</h2><div class="card"><h2 id="b">B</h2></div></section>` +
+		`<h2 id="c" class="title">C</h2>` +
+		`</div>` +
+		`<h2 id="d">D</h2>`
+	tests := []struct {
+		selector string
+		want     string // text of the matched h2, comma separated
+	}{
+		{"h2", "A,B,C,D"},
+		{"H2", "A,B,C,D"},
+		{"*#c", "C"},
+		{"h2.title", "C"},
+		{"div.content h2", "A,B,C"},
+		{"div.main.content h2", "A,B,C"},
+		{"div.content > h2", "C"},
+		{"section > h2", "A"},
+		{"#top section h2", "A,B"},
+		{"[data-kind] .card > h2", "B"},
+		{`[data-kind="crd"] h2`, "A,B"},
+		{"[data-kind=other] h2", ""},
+		{"div.card h2, h2#d", "B,D"},
+		{"h2#a,h2#a", "A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := scan(doc, sel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, el := range doc.matches[0] {
+				got = append(got, strings.TrimSpace(el.text))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Fatalf("matched %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		selector string
+		wantErr  string
+	}{
+		{"", "empty selector"},
+		{"div >", "missing selector after combinator"},
+		{"div + p", "unsupported '+'"},
+		{"div ~ p", "unsupported '~'"},
+		{"div:first-child", "unsupported ':'"},
+		{"#", "missing id"},
+		{"div.", "missing class"},
+		{"[", "missing attribute name"},
+		{"[a=b", "expected ']'"},
+		{`[a="b]`, "unterminated attribute value"},
+		{"div,", "missing selector after combinator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			_, err := ParseSelector(tt.selector)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
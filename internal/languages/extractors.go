@@ -0,0 +1,182 @@
+package languages
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reDQString    = regexp.MustCompile(`"(?:\\.|[^"\\])*"`) // "…", allows \" and escaped chars
+	reRawString   = regexp.MustCompile("`[^`]*`")           // `…` (no escapes inside)
+	reSQString    = regexp.MustCompile(`'(?:\\.|[^'\\])*'`) // '…', Python only
+	reIdent       = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)`)
+	reRustVis     = regexp.MustCompile(`^pub(\([^)]*\))?\s+`)
+	reSerdeRename = regexp.MustCompile(`rename\s*=\s*("(?:\\.|[^"\\])*")`)
+)
+
+// PythonExtractor reads a module or class level assignment, e.g. `READY: Final = "Ready"` or the member
+// of an Enum.
+type PythonExtractor struct{}
+
+func (PythonExtractor) Extract(lines []string) (map[string]string, error) {
+	line := strings.TrimSpace(lines[0])
+	name := firstIdent(line)
+	if name == "" {
+		return nil, fmt.Errorf("could not parse variable name from: %q", line)
+	}
+	value := name
+	if rhs, ok := pythonValue(line); ok {
+		if lit, ok := pythonStringLiteral(rhs); ok {
+			value = lit
+		}
+	}
+	return variable(name, value), nil
+}
+
+// pythonValue returns the value assigned by a Python assignment, without a trailing comment. The '='
+// is the first one outside of string literals and brackets, as the annotation may hold them too, e.g.
+// Literal["a=b"].
+func pythonValue(line string) (string, bool) {
+	depth := 0
+	assign := -1
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			line = line[:i] // ends the loop
+		case strings.IndexByte("([{", c) >= 0:
+			depth++
+		case strings.IndexByte(")]}", c) >= 0:
+			depth--
+		case c == '=' && depth == 0 && assign < 0:
+			assign = i
+		}
+	}
+	if assign < 0 {
+		return "", false
+	}
+	return line[assign+1:], true
+}
+
+// RustExtractor reads a const or static, an enum variant or a struct field. Attributes between the doc
+// comment and the item are skipped, also when they span several lines, a serde rename sets the
+// serialized value.
+type RustExtractor struct{}
+
+func (RustExtractor) Extract(lines []string) (map[string]string, error) {
+	var rename string
+	var attribute strings.Builder
+	depth := 0 // of the brackets of the attribute being read
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if depth > 0 || strings.HasPrefix(line, "#[") {
+			attribute.WriteString(line + " ")
+			depth += strings.Count(line, "[") - strings.Count(line, "]")
+			if depth > 0 {
+				continue
+			}
+			if m := reSerdeRename.FindStringSubmatch(attribute.String()); m != nil {
+				rename, _ = strconv.Unquote(m[1])
+			}
+			attribute.Reset()
+			depth = 0
+			continue
+		}
+
+		item := reRustVis.ReplaceAllString(line, "")
+		for _, kw := range []string{"const ", "static ", "mut "} {
+			item = strings.TrimSpace(strings.TrimPrefix(item, kw))
+		}
+		name := firstIdent(item)
+		if name == "" {
+			return nil, fmt.Errorf("could not parse item name from: %q", line)
+		}
+		value := name
+		if _, rhs, ok := strings.Cut(item, "="); ok {
+			if lit, ok := firstStringLiteral(rhs); ok {
+				value = lit
+			}
+		}
+		if rename != "" {
+			value = rename
+		}
+		return variable(name, value), nil
+	}
+	return nil, fmt.Errorf("could not find the item after the tag")
+}
+
+func variable(name, value string) map[string]string {
+	return map[string]string{
+		"const": name,
+		"value": value,
+		"type":  "string",
+	}
+}
+
+// firstIdent returns the first identifier from a line.
+func firstIdent(s string) string {
+	s = strings.TrimSpace(s)
+	for s != "" && (s[0] == '*' || s[0] == '&') {
+		s = s[1:]
+	}
+	// identifier: letters, numbers, underscores (start with letter or _)
+	m := reIdent.FindStringSubmatch(s)
+	if len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+// firstStringLiteral finds the first Go string literal in s (either "..." or `...`)
+// and returns its unquoted value.
+func firstStringLiteral(s string) (string, bool) {
+	di := reDQString.FindStringIndex(s)
+	ri := reRawString.FindStringIndex(s)
+
+	var start, end int
+	switch {
+	case di == nil && ri == nil:
+		return "", false
+	case di != nil && (ri == nil || di[0] < ri[0]):
+		start, end = di[0], di[1]
+	default:
+		start, end = ri[0], ri[1]
+	}
+
+	lit := s[start:end]
+	v, err := strconv.Unquote(lit)
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+// pythonStringLiteral returns the value of the first '...' or "..." literal in s.
+func pythonStringLiteral(s string) (string, bool) {
+	di := reDQString.FindStringIndex(s)
+	si := reSQString.FindStringIndex(s)
+	switch {
+	case di == nil && si == nil:
+		return "", false
+	case di != nil && (si == nil || di[0] < si[0]):
+		v, err := strconv.Unquote(s[di[0]:di[1]])
+		return v, err == nil
+	}
+	// re-quote the single quoted literal for strconv
+	body := s[si[0]+1 : si[1]-1]
+	body = strings.ReplaceAll(body, `\'`, `'`)
+	body = strings.ReplaceAll(body, `"`, `\"`)
+	v, err := strconv.Unquote(`"` + body + `"`)
+	return v, err == nil
+}
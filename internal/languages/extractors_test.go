@@ -0,0 +1,75 @@
+package languages
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+)
+
+type extractorTest struct {
+	name    string
+	lines   string // the lines following the comment, separated by "\n"
+	want    string // <const>=<value>
+	wantErr string
+}
+
+func runExtractorTests(t *testing.T, extractor tp.VariableExtractor, tests []extractorTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractor.Extract(strings.Split(tt.lines, "\n"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := fmt.Sprintf("%s=%s", got["const"], got["value"]); s != tt.want || got["type"] != "string" {
+				t.Errorf("got %s of type %s, want %s of type string", s, got["type"], tt.want)
+			}
+		})
+	}
+}
+
+func TestPythonExtractor(t *testing.T) {
+	runExtractorTests(t, PythonExtractor{}, []extractorTest{
+		{name: "assignment", lines: `READY = "Ready"`, want: "READY=Ready"},
+		{name: "single quotes", lines: `    STARTING = 'It\'s "starting"'`, want: `STARTING=It's "starting"`},
+		{name: "type hint", lines: `READY: Final = "Ready"`, want: "READY=Ready"},
+		{name: "generic type hint", lines: `READY: Final[str] = "Ready"`, want: "READY=Ready"},
+		{name: "strings in the type hint", lines: `MODE: Literal["a=b", "c"] = "c"`, want: "MODE=c"},
+		{name: "keyword arguments", lines: `READY = Condition(type="Ready")`, want: "READY=Ready"},
+		{name: "enum member without a string", lines: `READY = auto()`, want: "READY=READY"},
+		{name: "annotation only", lines: `ready: str`, want: "ready=ready"},
+		{name: "comment after the value", lines: `READY = 1  # = "not the value"`, want: "READY=READY"},
+		{name: "not a declaration", lines: `"Ready"`, wantErr: "could not parse variable name"},
+	})
+}
+
+func TestRustExtractor(t *testing.T) {
+	runExtractorTests(t, RustExtractor{}, []extractorTest{
+		{name: "const", lines: `pub const READY: &str = "Ready";`, want: "READY=Ready"},
+		{name: "restricted static", lines: `pub(crate) static READY: &'static str = "Ready";`, want: "READY=Ready"},
+		{name: "static mut", lines: `static mut READY: &str = "Ready";`, want: "READY=Ready"},
+		{name: "raw string", lines: `const READY: &str = r#"Ready"#;`, want: "READY=Ready"},
+		{name: "enum variant", lines: "    Ready,\n    Synced,", want: "Ready=Ready"},
+		{name: "struct field", lines: "pub ready: Condition,", want: "ready=ready"},
+		{
+			name:  "attributes",
+			lines: "#[default]\n#[serde(alias = \"ready\", rename = \"IsReady\")]\nReady,",
+			want:  "Ready=IsReady",
+		},
+		{
+			name:  "attribute spanning lines",
+			lines: "#[serde(\n    rename = \"IsReady\",\n    alias = \"ready\"\n)]\nReady,",
+			want:  "Ready=IsReady",
+		},
+		{name: "rename_all is not a rename", lines: "#[serde(rename_all = \"camelCase\")]\nReady,", want: "Ready=Ready"},
+		{name: "attributes only", lines: "#[derive(Debug)]", wantErr: "could not find the item"},
+	})
+}
@@ -0,0 +1,110 @@
+package languages
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	lhs "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/line_handlers"
+)
+
+// Language is the comment syntax and declaration extractor FileDocTagParser needs to find tags in the
+// sources of a programming language.
+type Language struct {
+	Name           string
+	Extensions     []string // including the dot, e.g. ".py"
+	CommentMatcher tp.LineMatcher
+	CommentTrimmer tp.LineTrimmer
+	BulletMatcher  tp.LineMatcher
+	Extractor      tp.VariableExtractor
+}
+
+// TagParser returns a FileDocTagParser for sources in the language.
+func (l Language) TagParser(parsers []tp.DocTagParser, normalizer tp.CommentNormalizer) *tp.FileDocTagParser {
+	return tp.NewFileTagParser(parsers, l.CommentMatcher, l.CommentTrimmer, normalizer, l.BulletMatcher, l.Extractor)
+}
+
+var (
+	// Go sources are parsed per package by tag_parser.GoTagParser rather than file by file, so Go only
+	// claims its extension and has no FileDocTagParser handlers.
+	Go = Language{
+		Name:       "go",
+		Extensions: []string{".go"},
+	}
+	// Python covers kopf operators documenting their conditions with # comments.
+	Python = Language{
+		Name:           "python",
+		Extensions:     []string{".py"},
+		CommentMatcher: lhs.PythonCommentMatcher{},
+		CommentTrimmer: lhs.PythonCommentTrimmer{},
+		BulletMatcher:  lhs.BulletPointMatcher{},
+		Extractor:      PythonExtractor{},
+	}
+	// Rust covers kube-rs operators documenting their conditions with /// doc comments.
+	Rust = Language{
+		Name:           "rust",
+		Extensions:     []string{".rs"},
+		CommentMatcher: lhs.RustCommentMatcher{},
+		CommentTrimmer: lhs.RustCommentTrimmer{},
+		BulletMatcher:  lhs.BulletPointMatcher{},
+		Extractor:      RustExtractor{},
+	}
+)
+
+// All is every supported language.
+var All = []Language{Go, Python, Rust}
+
+// Registry maps file extensions to the language of the file.
+type Registry struct {
+	byExtension map[string]Language
+}
+
+// NewRegistry returns a registry of the given languages. Two languages may not claim the same extension.
+func NewRegistry(langs ...Language) (*Registry, error) {
+	r := &Registry{byExtension: map[string]Language{}}
+	for _, l := range langs {
+		for _, ext := range l.Extensions {
+			ext = strings.ToLower(ext)
+			if other, ok := r.byExtension[ext]; ok {
+				return nil, fmt.Errorf("extension %s is claimed by both %s and %s", ext, other.Name, l.Name)
+			}
+			r.byExtension[ext] = l
+		}
+	}
+	return r, nil
+}
+
+// ByName returns the supported languages with the given names.
+func ByName(names []string) ([]Language, error) {
+	var langs []Language
+	for _, name := range names {
+		found := false
+		for _, l := range All {
+			if l.Name == strings.ToLower(strings.TrimSpace(name)) {
+				langs = append(langs, l)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unsupported language %q", name)
+		}
+	}
+	return langs, nil
+}
+
+// Lookup returns the language of a file by its extension.
+func (r *Registry) Lookup(filename string) (Language, bool) {
+	l, ok := r.byExtension[strings.ToLower(filepath.Ext(filename))]
+	return l, ok
+}
+
+// Has returns true if the language with the given name is registered.
+func (r *Registry) Has(name string) bool {
+	for _, l := range r.byExtension {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package languages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+)
+
+func TestTagParser(t *testing.T) {
+	tests := []struct {
+		lang Language
+		file string
+		src  string
+		want []string // <line> <tag type> <const>=<value> | <comment>
+	}{
+		{
+			lang: Python,
+			file: "conditions.py",
+			src: `from enum import Enum
+from typing import Final
+
+# Whether the gateway is ready.
+#  - status = True: all replicas are up
+# +cty:condition:for=ZeebeGateway
+GATEWAY_READY: Final[str] = "GatewayReady"
+
+
+class GatewayReason(str, Enum):
+    # The gateway pods are still starting.
+    # +cty:reason:for=ZeebeGateway/GatewayReady
+    STARTING = "Starting"
+`,
+			want: []string{
+				`7 condition GATEWAY_READY=GatewayReady | Whether the gateway is ready.\n- status = True: all replicas are up`,
+				"13 reason STARTING=Starting | The gateway pods are still starting.",
+			},
+		},
+		{
+			lang: Rust,
+			file: "conditions.rs",
+			src: `/// Whether the broker joined the cluster.
+/// +cty:condition:for=ZeebeBroker
+pub const JOINED: &str = "Joined";
+
+#[derive(Serialize)]
+pub enum BrokerReason {
+    /// The broker is waiting for its peers.
+    /// +cty:reason:for=ZeebeBroker/Joined
+    #[serde(
+        rename = "WaitingForPeers"
+    )]
+    Waiting,
+}
+`,
+			want: []string{
+				"3 condition JOINED=Joined | Whether the broker joined the cluster.",
+				"9 reason Waiting=WaitingForPeers | The broker is waiting for its peers.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang.Name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(filename, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			registry, err := NewRegistry(All...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lang, ok := registry.Lookup(filename); !ok || lang.Name != tt.lang.Name {
+				t.Fatalf("language of %s is %q, want %s", tt.file, lang.Name, tt.lang.Name)
+			}
+
+			diags := diagnostics.NewCollector()
+			parser := tt.lang.TagParser([]tp.DocTagParser{tps.ConditionTagParser{}, tps.ReasonTagParser{}}, nil)
+			var got []string
+			for _, r := range parser.ParseTags(filename, diags) {
+				got = append(got, fmt.Sprintf(
+					"%d %s %s=%s | %s", r.Source.Line, r.Type, r.Variable["const"], r.Variable["value"],
+					strings.ReplaceAll(r.Comment, "\n", `\n`),
+				))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("results\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if d := diags.Diagnostics(); len(d) > 0 {
+				t.Errorf("diagnostics %v", d)
+			}
+		})
+	}
+}
+
+func TestByName(t *testing.T) {
+	langs, err := ByName([]string{"go", " Python "})
+	if err != nil || len(langs) != 2 || langs[0].Name != "go" || langs[1].Name != "python" {
+		t.Errorf("ByName = %v, %v, want go and python", langs, err)
+	}
+	if _, err := ByName([]string{"go", "java"}); err == nil || !strings.Contains(err.Error(), `"java"`) {
+		t.Errorf("error %v, want one naming java", err)
+	}
+}
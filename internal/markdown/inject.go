@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Markers delimiting the generated part of a hand-written Markdown file. The begin marker may select
+// the CRDs to render with a comma separated crd= list:
+//
+//	<!-- cty-conditions:begin crd=ZeebeCluster,zeebe.camunda.io/v1alpha1/ZeebeGateway -->
+//	<!-- cty-conditions:end -->
+var (
+	reBeginMarker = regexp.MustCompile(`^\s*<!--\s*cty-conditions:begin\b(.*?)-->\s*$`)
+	reEndMarker   = regexp.MustCompile(`^\s*<!--\s*cty-conditions:end\s*-->\s*$`)
+	reFence       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+)
+
+// Marker is a begin marker found in a Markdown file.
+type Marker struct {
+	Line int // 1-based
+	// CRDs are the values of the crd= argument, empty if the marker does not select any.
+	CRDs []string
+}
+
+// InjectBetweenMarkers replaces the lines between every begin and end marker in doc with what render
+// returns for the begin marker, and returns the updated document and the number of regions. The
+// markers themselves are kept, so the document can be updated again. Markers inside fenced code blocks
+// are left alone, the lines of a region are not looked at but for its end marker.
+func InjectBetweenMarkers(doc string, render func(m Marker) (string, error)) (string, int, error) {
+	lines := strings.Split(doc, "\n")
+	var out []string
+	var begin *Marker
+	var fence string
+	regions := 0
+
+	for i, line := range lines {
+		if begin == nil {
+			out = append(out, line)
+			// a fence is closed by a fence of the same character that is at least as long, without an info
+			// string
+			if m := reFence.FindStringSubmatch(line); m != nil {
+				switch {
+				case fence == "":
+					fence = m[1]
+				case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(m[2]) == "":
+					fence = ""
+				}
+				continue
+			}
+			if fence != "" {
+				continue
+			}
+		}
+
+		if m := reBeginMarker.FindStringSubmatch(line); m != nil {
+			if begin != nil {
+				return "", 0, fmt.Errorf("line %d: cty-conditions:begin inside the region opened at line %d", i+1, begin.Line)
+			}
+			marker, err := parseMarker(i+1, m[1])
+			if err != nil {
+				return "", 0, err
+			}
+			begin = &marker
+			continue
+		}
+		if reEndMarker.MatchString(line) {
+			if begin == nil {
+				return "", 0, fmt.Errorf("line %d: cty-conditions:end without a cty-conditions:begin", i+1)
+			}
+			content, err := render(*begin)
+			if err != nil {
+				return "", 0, fmt.Errorf("line %d: %w", begin.Line, err)
+			}
+			// blank lines keep the content from being read as part of the HTML comments
+			out = append(out, "", strings.TrimRight(content, "\n"), "", line)
+			begin = nil
+			regions++
+		}
+	}
+	if begin != nil {
+		return "", 0, fmt.Errorf("line %d: cty-conditions:begin without a cty-conditions:end", begin.Line)
+	}
+	return strings.Join(out, "\n"), regions, nil
+}
+
+// parseMarker parses the space separated key=value arguments of a begin marker.
+func parseMarker(line int, args string) (Marker, error) {
+	marker := Marker{Line: line}
+	for _, arg := range strings.Fields(args) {
+		key, value, _ := strings.Cut(arg, "=")
+		switch key {
+		case "crd":
+			for _, crd := range strings.Split(value, ",") {
+				if crd = strings.TrimSpace(crd); crd == "" {
+					return Marker{}, fmt.Errorf("line %d: empty value in crd=%s", line, value)
+				}
+				marker.CRDs = append(marker.CRDs, strings.TrimSpace(crd))
+			}
+		default:
+			return Marker{}, fmt.Errorf("line %d: unknown argument %q of cty-conditions:begin, expected crd", line, arg)
+		}
+	}
+	return marker, nil
+}
@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// renderMarker renders the CRDs a marker selects, "all" if it does not select any.
+func renderMarker(m Marker) (string, error) {
+	if len(m.CRDs) == 0 {
+		return "all\n", nil
+	}
+	return strings.Join(m.CRDs, "\n") + "\n", nil
+}
+
+func TestInjectBetweenMarkers(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		want        string
+		wantRegions int
+		wantErr     string
+	}{
+		{
+			name:        "no markers",
+			doc:         "# API\n\ntext\n",
+			want:        "# API\n\ntext\n",
+			wantRegions: 0,
+		},
+		{
+			name: "a region",
+			doc:  "# API\n<!-- cty-conditions:begin -->\nold\ncontent\n<!-- cty-conditions:end -->\ntext\n",
+			want: "# API\n<!-- cty-conditions:begin -->\n\nall\n\n<!-- cty-conditions:end -->\ntext\n",
+
+			wantRegions: 1,
+		},
+		{
+			name: "repeated regions",
+			doc: "<!-- cty-conditions:begin crd=Cluster -->\n<!-- cty-conditions:end -->\n" +
+				"between\n" +
+				"  <!--cty-conditions:begin crd=Gateway,example.com/v1/Broker-->  \n<!-- cty-conditions:end -->",
+			want: "<!-- cty-conditions:begin crd=Cluster -->\n\nCluster\n\n<!-- cty-conditions:end -->\n" +
+				"between\n" +
+				"  <!--cty-conditions:begin crd=Gateway,example.com/v1/Broker-->  \n\nGateway\nexample.com/v1/Broker\n\n" +
+				"<!-- cty-conditions:end -->",
+			wantRegions: 2,
+		},
+		{
+			name: "markers in fenced code blocks",
+			doc: "```html\n<!-- cty-conditions:begin -->\n<!-- cty-conditions:end -->\n```\n" +
+				"~~~~\n~~~ not closed\n```\n<!-- cty-conditions:end -->\n~~~~\n" +
+				"<!-- cty-conditions:begin -->\n<!-- cty-conditions:end -->\n",
+			want: "```html\n<!-- cty-conditions:begin -->\n<!-- cty-conditions:end -->\n```\n" +
+				"~~~~\n~~~ not closed\n```\n<!-- cty-conditions:end -->\n~~~~\n" +
+				"<!-- cty-conditions:begin -->\n\nall\n\n<!-- cty-conditions:end -->\n",
+			wantRegions: 1,
+		},
+		{
+			name: "fences inside a region are replaced along with it",
+			doc:  "<!-- cty-conditions:begin -->\n```\n<!-- cty-conditions:end -->\ntext\n",
+			want: "<!-- cty-conditions:begin -->\n\nall\n\n<!-- cty-conditions:end -->\ntext\n",
+
+			wantRegions: 1,
+		},
+		{
+			name:    "begin without end",
+			doc:     "text\n<!-- cty-conditions:begin -->\nold\n",
+			wantErr: "line 2: cty-conditions:begin without a cty-conditions:end",
+		},
+		{
+			name:    "end without begin",
+			doc:     "text\n<!-- cty-conditions:end -->\n",
+			wantErr: "line 2: cty-conditions:end without a cty-conditions:begin",
+		},
+		{
+			name: "nested regions",
+			doc: "<!-- cty-conditions:begin crd=Cluster -->\n<!-- cty-conditions:begin crd=Gateway -->\n" +
+				"<!-- cty-conditions:end -->\n<!-- cty-conditions:end -->\n",
+			wantErr: "line 2: cty-conditions:begin inside the region opened at line 1",
+		},
+		{
+			name:    "unknown argument",
+			doc:     "<!-- cty-conditions:begin kind=Cluster -->\n<!-- cty-conditions:end -->\n",
+			wantErr: `line 1: unknown argument "kind=Cluster"`,
+		},
+		{
+			name:    "empty CRD",
+			doc:     "<!-- cty-conditions:begin crd=Cluster,,Gateway -->\n<!-- cty-conditions:end -->\n",
+			wantErr: "line 1: empty value in crd=Cluster,,Gateway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, regions, err := InjectBetweenMarkers(tt.doc, renderMarker)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || regions != tt.wantRegions {
+				t.Fatalf("got %d regions\n%s\nwant %d\n%s", regions, got, tt.wantRegions, tt.want)
+			}
+
+			again, _, err := InjectBetweenMarkers(got, renderMarker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Errorf("injecting again changed the document:\n%s\nwant\n%s", again, got)
+			}
+		})
+	}
+}
+
+func TestInjectBetweenMarkersRenderError(t *testing.T) {
+	doc := "text\n<!-- cty-conditions:begin crd=Cluster -->\n<!-- cty-conditions:end -->\n"
+	_, _, err := InjectBetweenMarkers(doc, func(m Marker) (string, error) {
+		return "", fmt.Errorf("no CRD %s", m.CRDs[0])
+	})
+	if err == nil || err.Error() != "line 2: no CRD Cluster" {
+		t.Errorf("error %v, want line 2: no CRD Cluster", err)
+	}
+}
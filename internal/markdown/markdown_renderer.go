@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	cf "github.com/sourcehawk/cty-generator-addons/internal/comment_format"
+)
+
+type Generator interface {
+	Generate() (string, error)
+	AddChild(child Generator)
+}
+
+type BaseMarkdownGenerator struct {
+	Template *template.Template
+	Children []Generator
+}
+
+func (g *BaseMarkdownGenerator) RenderChildren() ([]string, error) {
+	var parts []string
+
+	for _, child := range g.Children {
+		md, err := child.Generate()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, md)
+	}
+
+	return parts, nil
+}
+
+func (g *BaseMarkdownGenerator) AddChild(child Generator) {
+	g.Children = append(g.Children, child)
+}
+
+// ExecTemplate a helper used by nodes to execute their local template.
+func (g *BaseMarkdownGenerator) ExecTemplate(name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if name == "" {
+		if err := g.Template.Execute(&buf, data); err != nil {
+			return "", err
+		}
+	} else {
+		if err := g.Template.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+// Converts plain text (with lines starting `-`, `•`, or `*`) into Markdown paragraphs and lists, using
+// the same rules as the HTML renderers. The text is escaped, so it reads the same as in the HTML.
+func formatCommentMarkdown(s string) string {
+	var out []string
+	for _, b := range cf.Parse(s) {
+		switch b.Kind {
+		case cf.Paragraph:
+			out = append(out, escapeText(b.Text))
+		case cf.BulletList:
+			items := make([]string, len(b.Items))
+			for i, item := range b.Items {
+				items[i] = "- " + escapeText(item)
+			}
+			out = append(out, strings.Join(items, "\n"))
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// Converts plain text like formatCommentMarkdown, but on a single line for a table cell: paragraphs are
+// separated by <br><br> and bullet points by <br>.
+func formatCommentCell(s string) string {
+	var out []string
+	for _, b := range cf.Parse(s) {
+		switch b.Kind {
+		case cf.Paragraph:
+			out = append(out, escapeCell(b.Text))
+		case cf.BulletList:
+			items := make([]string, len(b.Items))
+			for i, item := range b.Items {
+				items[i] = "• " + escapeCell(item)
+			}
+			out = append(out, strings.Join(items, "<br>"))
+		}
+	}
+	return strings.Join(out, "<br><br>")
+}
+
+var inlineEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`,
+)
+
+// escapeText escapes the characters Markdown would interpret in a line of plain text.
+func escapeText(s string) string {
+	s = inlineEscaper.Replace(s)
+	// at the start of a line these would begin a heading, list or quote
+	if s != "" && strings.ContainsRune("#+-", rune(s[0])) {
+		s = `\` + s
+	}
+	return s
+}
+
+// escapeCell escapes plain text for a table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(escapeText(s), "|", `\|`)
+}
+
+// code renders s as inline code, with a longer fence if s contains backticks.
+func code(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// Renders whether a condition being True is normal or abnormal, empty if unset.
+func polarityText(polarity string) string {
+	switch polarity {
+	case "positive":
+		return "Normal when True"
+	case "negative":
+		return "Abnormal when True"
+	}
+	return ""
+}
+
+var tmplFuncs = template.FuncMap{
+	"formatComment": formatCommentMarkdown,
+	"formatCell":    formatCommentCell,
+	"escape":        escapeText,
+	"escapeCell":    escapeCell,
+	"code":          code,
+	"polarity":      polarityText,
+}
+
+func MustParseTemplate(localName, src string) *template.Template {
+	return template.Must(template.New(localName).Funcs(tmplFuncs).Parse(src))
+}
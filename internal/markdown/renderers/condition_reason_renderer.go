@@ -0,0 +1,50 @@
+package renderers
+
+import (
+	mr "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+// A reason is a row of the reasons table of its condition.
+const reasonTemplate = `| <a id="{{ .Anchor }}"></a>{{ code .Name }}
+{{- if .DisplayName }} ({{ escapeCell .DisplayName }}){{ end }}
+{{- if .Shared }} _shared_{{ end }}
+{{- if eq .Provenance.Origin "catalog" }} _catalog_{{ end }} | {{ escapeCell .Severity }} | {{ formatCell .Description }} | {{ .GoAPI }} |
+`
+
+type ReasonNode struct {
+	mr.BaseMarkdownGenerator
+
+	CRD       model.CRD
+	Condition string
+	Reason    model.ReasonDoc
+}
+
+func NewReasonNode(crd model.CRD, condition string, reason model.ReasonDoc) *ReasonNode {
+	return &ReasonNode{
+		BaseMarkdownGenerator: mr.BaseMarkdownGenerator{
+			Template: mr.MustParseTemplate("reason", reasonTemplate),
+		},
+		CRD:       crd,
+		Condition: condition,
+		Reason:    reason,
+	}
+}
+
+func (n *ReasonNode) Generate() (string, error) {
+	goAPI, err := NewGoAPINode(n.Reason.Decl).Generate()
+	if err != nil {
+		return "", err
+	}
+	data := map[string]any{
+		"Name":        n.Reason.Name,
+		"DisplayName": n.Reason.DisplayName,
+		"Severity":    n.Reason.Severity,
+		"Shared":      n.Reason.Shared,
+		"Description": n.Reason.Description,
+		"Provenance":  n.Reason.Provenance,
+		"GoAPI":       goAPI,
+		"Anchor":      n.CRD.ReasonAnchor(n.Condition, n.Reason.Name),
+	}
+	return n.ExecTemplate("", data)
+}
@@ -0,0 +1,83 @@
+package renderers
+
+import (
+	"strings"
+
+	mr "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+// The properties of a condition are a definition list, as supported by MkDocs (def_list) and most
+// Markdown Extra flavours, the reasons a table.
+const conditionTemplate = `<a id="{{ .Anchor }}"></a>
+
+#### {{ escape .Name }}
+
+{{ if .Description }}{{ formatComment .Description }}
+
+{{ end }}Type
+: {{ code .Type }}
+{{ if .DisplayName }}
+Display name
+: {{ escape .DisplayName }}
+{{ end }}{{ if .Polarity }}
+Polarity
+: {{ polarity .Polarity }}
+{{ end }}{{ if .Severity }}
+Severity
+: {{ escape .Severity }}
+{{ end }}{{ if .GoAPI }}
+Go API
+: {{ .GoAPI }}
+{{ end }}{{ if eq .Provenance.Origin "catalog" }}
+Catalog
+: {{ code .Provenance.File }}
+{{ end }}
+**Reasons**
+
+{{ if .HasChildren }}| Reason | Severity | Description | Go API |
+|--------|----------|-------------|--------|
+{{ .Children }}{{ else }}_No specific reasons documented._
+{{ end }}`
+
+type ConditionNode struct {
+	mr.BaseMarkdownGenerator
+
+	CRD       model.CRD
+	Condition model.ConditionDoc
+}
+
+func NewConditionNode(crd model.CRD, condition model.ConditionDoc) *ConditionNode {
+	return &ConditionNode{
+		BaseMarkdownGenerator: mr.BaseMarkdownGenerator{
+			Template: mr.MustParseTemplate("condition", conditionTemplate),
+		},
+		CRD:       crd,
+		Condition: condition,
+	}
+}
+
+func (n *ConditionNode) Generate() (string, error) {
+	parts, err := n.RenderChildren()
+	if err != nil {
+		return "", err
+	}
+	goAPI, err := NewGoAPINode(n.Condition.Decl).Generate()
+	if err != nil {
+		return "", err
+	}
+	data := map[string]any{
+		"Name":        n.Condition.Name,
+		"DisplayName": n.Condition.DisplayName,
+		"Type":        n.Condition.Type,
+		"Polarity":    n.Condition.Polarity,
+		"Severity":    n.Condition.Severity,
+		"Description": n.Condition.Description,
+		"Provenance":  n.Condition.Provenance,
+		"GoAPI":       goAPI,
+		"Anchor":      n.CRD.ConditionAnchor(n.Condition.Name),
+		"HasChildren": len(parts) > 0,
+		"Children":    strings.Join(parts, ""),
+	}
+	return n.ExecTemplate("", data)
+}
@@ -0,0 +1,48 @@
+package renderers
+
+import (
+	"strings"
+
+	mr "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+const crdTemplate = `<a id="{{ .Anchor }}"></a>
+
+### {{ escape .Name }}{{ if .GroupVersion }} ({{ code .GroupVersion }}){{ end }}
+
+Condition types for the {{ escape .Name }} resource{{ if .Version }} in version {{ escape .Version }}{{ end }}.
+
+{{ if .HasChildren }}{{ .Children }}{{ else }}_No conditions documented for this resource._
+{{ end }}`
+
+type CRDNode struct {
+	mr.BaseMarkdownGenerator
+
+	CRD model.CRD
+}
+
+func NewCRDNode(crd model.CRD) *CRDNode {
+	return &CRDNode{
+		BaseMarkdownGenerator: mr.BaseMarkdownGenerator{
+			Template: mr.MustParseTemplate("crd", crdTemplate),
+		},
+		CRD: crd,
+	}
+}
+
+func (n *CRDNode) Generate() (string, error) {
+	parts, err := n.RenderChildren()
+	if err != nil {
+		return "", err
+	}
+	data := map[string]any{
+		"Name":         n.CRD.Name,
+		"GroupVersion": n.CRD.GroupVersion(),
+		"Version":      n.CRD.Version,
+		"Anchor":       n.CRD.Anchor(),
+		"HasChildren":  len(parts) > 0,
+		"Children":     strings.Join(parts, "\n"),
+	}
+	return n.ExecTemplate("", data)
+}
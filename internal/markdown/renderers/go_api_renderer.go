@@ -0,0 +1,34 @@
+package renderers
+
+import (
+	mr "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+// Rendered on a single line, so it fits both a definition and a table cell.
+const goAPITemplate = `{{ if .Ident -}}
+{{ code .Ident }}
+{{- if .TypeName }} {{ if eq .Kind "field" }}field of{{ else }}of type{{ end }} {{ code .TypeName }}{{ end }}
+{{- if .URL }} ([{{ escapeCell .File }}:{{ .Line }}]({{ .URL }}))
+{{- else if .File }} ({{ code (printf "%s:%d" .File .Line) }}){{ end }}
+{{- end }}`
+
+// GoAPINode renders the Go declaration a condition or reason is documented on, nothing if it has none.
+type GoAPINode struct {
+	mr.BaseMarkdownGenerator
+
+	Decl model.GoDecl
+}
+
+func NewGoAPINode(decl model.GoDecl) *GoAPINode {
+	return &GoAPINode{
+		BaseMarkdownGenerator: mr.BaseMarkdownGenerator{
+			Template: mr.MustParseTemplate("goAPI", goAPITemplate),
+		},
+		Decl: decl,
+	}
+}
+
+func (n *GoAPINode) Generate() (string, error) {
+	return n.ExecTemplate("", n.Decl)
+}
@@ -0,0 +1,95 @@
+package renderers
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mr "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+	"github.com/sourcehawk/cty-generator-addons/internal/model"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var crds = []model.CRD{
+	{
+		Name: "ZeebeCluster", Group: "zeebe.example.com", Version: "v1",
+		Conditions: []model.ConditionDoc{
+			{
+				Name: "Ready", Type: "string", DisplayName: "Cluster *ready*", Polarity: "positive", Severity: "Error",
+				Description: "Ready tells whether the <cluster> is ready.\n" +
+					"It is set by the `reconciler`.\n" +
+					"- status = True: all brokers are up\n" +
+					"- status = False: see the reason | or the message\n" +
+					"\n" +
+					"# not a heading",
+				Provenance: model.Provenance{Origin: model.OriginSource, File: "api/v1/types.go", Line: 12},
+				Decl: model.GoDecl{
+					Ident: "v1.ClusterReady", Kind: "const", TypeName: "ConditionType",
+					File: "api/v1/types.go", Line: 12, URL: "https://example.com/api/v1/types.go#L12",
+				},
+				Reasons: []model.ReasonDoc{
+					{
+						Name: "Starting", Severity: "Info", DisplayName: "Starting_up",
+						Description: "The brokers are starting.\n- first\n- second | third",
+						Provenance:  model.Provenance{Origin: model.OriginSource, File: "api/v1/types.go", Line: 20},
+						Decl: model.GoDecl{
+							Ident: "v1.ReasonStarting", Kind: "const", TypeName: "string",
+							File: "api/v1/types.go", Line: 20,
+						},
+					},
+					{
+						Name: "Unreachable", Severity: "Warning", Shared: true,
+						Provenance: model.Provenance{Origin: model.OriginCatalog, File: "catalog.yaml", Line: 3},
+					},
+				},
+			},
+			{
+				Name: "Phase", Type: "ClusterPhase", Polarity: "negative",
+				Provenance: model.Provenance{Origin: model.OriginCatalog, File: "catalog.yaml", Line: 9},
+			},
+		},
+	},
+	{Name: "Gateway"},
+}
+
+func TestMarkdownGolden(t *testing.T) {
+	section := NewSectionNode("Conditions of `zeebe` [operator]")
+	for _, crd := range crds {
+		crdNode := NewCRDNode(crd)
+		for _, cond := range crd.Conditions {
+			condNode := NewConditionNode(crd, cond)
+			for _, r := range cond.Reasons {
+				condNode.AddChild(NewReasonNode(crd, cond.Name, r))
+			}
+			crdNode.AddChild(condNode)
+		}
+		section.AddChild(crdNode)
+	}
+	assertGolden(t, section, "section.md")
+	assertGolden(t, NewSectionNode("Conditions"), "empty_section.md")
+}
+
+// assertGolden compares the output of g with testdata/name, which go test -update rewrites.
+func assertGolden(t *testing.T, g mr.Generator, name string) {
+	t.Helper()
+	got, err := g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s changed, go test -update rewrites it:\n%s", golden, got)
+	}
+}
@@ -0,0 +1,41 @@
+package renderers
+
+import (
+	"strings"
+
+	mr "github.com/sourcehawk/cty-generator-addons/internal/markdown"
+)
+
+const sectionTemplate = `## {{ escape .Title }}
+
+Condition types & reasons.
+
+{{ if .HasChildren }}{{ .Children }}{{ else }}_No conditions found._
+{{ end }}`
+
+type SectionNode struct {
+	mr.BaseMarkdownGenerator
+	Title string
+}
+
+func NewSectionNode(title string) *SectionNode {
+	return &SectionNode{
+		BaseMarkdownGenerator: mr.BaseMarkdownGenerator{
+			Template: mr.MustParseTemplate("section", sectionTemplate),
+		},
+		Title: title,
+	}
+}
+
+func (n *SectionNode) Generate() (string, error) {
+	parts, err := n.RenderChildren()
+	if err != nil {
+		return "", err
+	}
+	data := map[string]any{
+		"Title":       n.Title,
+		"HasChildren": len(parts) > 0,
+		"Children":    strings.Join(parts, "\n"),
+	}
+	return n.ExecTemplate("", data)
+}
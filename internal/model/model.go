@@ -0,0 +1,110 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The aggregated CRD -> Condition -> Reason documentation model shared by the renderers.
+
+type ReasonDoc struct {
+	Name        string // string literal value (e.g. "CreationError")
+	ConstName   string // Go const identifier
+	DisplayName string // human-readable name, optional
+	Severity    string // Info, Warning or Error, optional
+	Shared      bool   // documented for several conditions through a list or wildcard tag
+	Description string // from comments
+	Decl        GoDecl
+	Provenance  Provenance
+}
+
+type ConditionDoc struct {
+	Name        string // string literal value or JSON field name (e.g. "Ready", "EncryptionReady")
+	ConstName   string // Go const or field identifier
+	Type        string // "string" for constants, the Go type for struct fields
+	DisplayName string // human-readable name, optional
+	Polarity    string // "positive" (True is normal) or "negative" (True is abnormal), optional
+	Severity    string // Info, Warning or Error, optional
+	Description string
+	Decl        GoDecl     // empty for conditions only named by reasons
+	Provenance  Provenance // empty for conditions only named by reasons
+	Reasons     []ReasonDoc
+}
+
+// Origins of a documented condition or reason.
+const (
+	OriginSource  = "source"  // a tagged declaration
+	OriginCatalog = "catalog" // an entry of a catalog file
+)
+
+// Provenance tells where a condition or reason was documented.
+type Provenance struct {
+	Origin string // OriginSource or OriginCatalog
+	File   string
+	Line   int
+}
+
+// GoDecl identifies the Go declaration a condition or reason is documented on.
+type GoDecl struct {
+	ImportPath  string // e.g. "example.com/api/v1alpha1"
+	PackageName string // e.g. "v1alpha1"
+	Ident       string // package qualified identifier (e.g. "v1alpha1.EncryptionCreationError")
+	Kind        string // "const", "var" or "field"
+	TypeName    string // Go type of a const or var, the struct of a field, optional
+	File        string // source file, relative to the source root
+	Line        int
+	URL         string // link to the declaration, empty unless a source URL template is configured
+}
+
+type CRD struct {
+	Name       string // Kind
+	Group      string // API group, empty if unknown
+	Version    string // API version, empty if unknown
+	Conditions []ConditionDoc
+}
+
+// GroupVersion returns the "<group>/<version>" the CRD is served under, or "" if it is unknown.
+func (c CRD) GroupVersion() string {
+	switch {
+	case c.Group == "":
+		return c.Version
+	case c.Version == "":
+		return c.Group
+	}
+	return c.Group + "/" + c.Version
+}
+
+// Anchor returns the id the documentation of the CRD is linked with. It only depends on the Kind,
+// group and version, so links stay valid across runs.
+func (c CRD) Anchor() string {
+	return "cty-" + slug(c.Name+" "+c.GroupVersion())
+}
+
+// ConditionAnchor returns the id of a condition of the CRD.
+func (c CRD) ConditionAnchor(condition string) string {
+	return c.Anchor() + "--" + slug(condition)
+}
+
+// ReasonAnchor returns the id of a reason of a condition of the CRD.
+func (c CRD) ReasonAnchor(condition, reason string) string {
+	return c.ConditionAnchor(condition) + "--" + slug(reason)
+}
+
+// slug lowercases s and replaces every run of characters other than letters and digits with a single
+// "-", so the parts of an anchor can be joined with "--".
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package comment_normalizers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MarkerLineDropper drops the lines that are meant for tools rather than readers: markers such as
+// +kubebuilder:validation:Optional, +cty:reason:..., +groupName=... or +optional, and directives such as
+// nolint:lll, go:generate or Python's noqa and type: ignore. Other lines starting with '+', like
+// "+1 retry" or "+ added", are text.
+type MarkerLineDropper struct{}
+
+var (
+	reMarker    = regexp.MustCompile(`^\+[A-Za-z][\w.-]*([:=]|$)`)
+	reDirective = regexp.MustCompile(`^(nolint\b|lint:|go:\w|noqa\b|pylint:|type: ?ignore\b|fmt: ?(on|off|skip)\b)`)
+)
+
+func (MarkerLineDropper) Normalize(lines []string, _ string) []string {
+	var out []string
+	for _, line := range lines {
+		t := strings.TrimSpace(line)
+		if reMarker.MatchString(t) || reDirective.MatchString(t) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// LeadingIdentStripper removes the identifier Go doc comments conventionally start with, so that
+// "ZeebeClusterReadyCondition indicates whether..." renders as "indicates whether...". The case of what
+// follows is kept, it may be a name.
+type LeadingIdentStripper struct{}
+
+func (LeadingIdentStripper) Normalize(lines []string, ident string) []string {
+	for i, line := range lines {
+		t := strings.TrimSpace(line)
+		if t == "" {
+			continue
+		}
+		// only the first line with text can start with the identifier
+		rest, ok := strings.CutPrefix(t, ident)
+		switch {
+		case ident == "" || !ok:
+		case rest == "":
+			return append(lines[:i:i], lines[i+1:]...)
+		case rest[0] == ' ':
+			out := append([]string{}, lines...)
+			out[i] = strings.TrimSpace(rest)
+			return out
+		}
+		return lines
+	}
+	return lines
+}
+
+// PatternStripper removes every match of its patterns from the comment lines, lines left empty by
+// that are dropped.
+type PatternStripper struct {
+	patterns []*regexp.Regexp
+}
+
+func NewPatternStripper(patterns []string) (*PatternStripper, error) {
+	ps := &PatternStripper{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid strip pattern %q: %w", p, err)
+		}
+		ps.patterns = append(ps.patterns, re)
+	}
+	return ps, nil
+}
+
+func (ps *PatternStripper) Normalize(lines []string, _ string) []string {
+	var out []string
+	for _, line := range lines {
+		stripped := line
+		for _, re := range ps.patterns {
+			stripped = re.ReplaceAllString(stripped, "")
+		}
+		if strings.TrimSpace(stripped) == "" && strings.TrimSpace(line) != "" {
+			continue
+		}
+		out = append(out, strings.TrimRight(stripped, " \t"))
+	}
+	return out
+}
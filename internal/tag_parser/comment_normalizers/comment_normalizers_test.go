@@ -0,0 +1,111 @@
+package comment_normalizers
+
+import (
+	"strings"
+	"testing"
+
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+)
+
+type normalizerTest struct {
+	name  string
+	lines string // separated by "\n"
+	ident string
+	want  string
+}
+
+func runNormalizerTests(t *testing.T, n tp.CommentNormalizer, tests []normalizerTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(n.Normalize(strings.Split(tt.lines, "\n"), tt.ident), "\n")
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkerLineDropper(t *testing.T) {
+	runNormalizerTests(t, MarkerLineDropper{}, []normalizerTest{
+		{
+			name: "markers",
+			lines: "Ready is true\n+cty:condition:for=Cluster\n  +kubebuilder:validation:Optional\n+optional\n" +
+				"+groupName=example.com\n+k8s:deepcopy-gen=package\nwhen ready",
+			want: "Ready is true\nwhen ready",
+		},
+		{
+			name:  "directives",
+			lines: "Ready\nnolint:lll\ngo:generate stringer\nnoqa: E501\ntype: ignore\nfmt: off",
+			want:  "Ready",
+		},
+		{
+			name:  "text starting with +",
+			lines: "Retries:\n+1 retry per minute\n+ added in v2\n+Ready is set too\n+/- 5 seconds",
+			want:  "Retries:\n+1 retry per minute\n+ added in v2\n+Ready is set too\n+/- 5 seconds",
+		},
+	})
+}
+
+func TestLeadingIdentStripper(t *testing.T) {
+	runNormalizerTests(t, LeadingIdentStripper{}, []normalizerTest{
+		{
+			name:  "identifier followed by text",
+			lines: "ZeebeClusterReady indicates whether\nthe cluster is ready.",
+			ident: "ZeebeClusterReady",
+			want:  "indicates whether\nthe cluster is ready.",
+		},
+		{
+			name:  "the case is kept",
+			lines: "Ready ZeebeCluster is ready.",
+			ident: "Ready",
+			want:  "ZeebeCluster is ready.",
+		},
+		{
+			name:  "after blank lines",
+			lines: "\n  Ready is true",
+			ident: "Ready",
+			want:  "\nis true",
+		},
+		{
+			name:  "a line holding only the identifier",
+			lines: "Ready\nis true",
+			ident: "Ready",
+			want:  "is true",
+		},
+		{
+			name:  "a longer identifier",
+			lines: "ReadyCondition is true",
+			ident: "Ready",
+			want:  "ReadyCondition is true",
+		},
+		{
+			name:  "only the first line",
+			lines: "The cluster is\nReady when started",
+			ident: "Ready",
+			want:  "The cluster is\nReady when started",
+		},
+		{
+			name:  "no identifier",
+			lines: " is true",
+			want:  " is true",
+		},
+	})
+}
+
+func TestPatternStripper(t *testing.T) {
+	ps, err := NewPatternStripper([]string{`TODO\(.*`, `See JIRA-[0-9]+\.`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runNormalizerTests(t, ps, []normalizerTest{
+		{
+			name:  "matches are removed, lines left empty dropped",
+			lines: "Ready is true. See JIRA-12.\nTODO(me): rename\n\nwhen ready  TODO(me)",
+			want:  "Ready is true.\n\nwhen ready",
+		},
+	})
+	if _, err := NewPatternStripper([]string{"("}); err == nil || !strings.Contains(err.Error(), `"("`) {
+		t.Errorf("error %v, want one naming the invalid pattern", err)
+	}
+}
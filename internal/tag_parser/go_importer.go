@@ -0,0 +1,97 @@
+package tag_parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// sourceImporter type-checks imported packages from source, like the "source" importer of go/importer.
+// Unlike that one it resolves import paths with its own build.Context instead of build.Default, whose
+// Dir selects the module the go command resolves them in. Imported packages are cached, so packages
+// sharing dependencies type-check them once.
+type sourceImporter struct {
+	ctxt     build.Context
+	fset     *token.FileSet
+	sizes    types.Sizes
+	packages map[string]*types.Package
+}
+
+// importing marks a package in sourceImporter.packages while it is being imported, to detect cycles.
+var importing types.Package
+
+// newSourceImporter returns an importer resolving import paths in the module in dir.
+func newSourceImporter(fset *token.FileSet, dir string) *sourceImporter {
+	ctxt := build.Default
+	ctxt.Dir = dir
+	// cgo is not run, pick the pure Go variants of packages instead
+	ctxt.CgoEnabled = false
+	return &sourceImporter{
+		ctxt:     ctxt,
+		fset:     fset,
+		sizes:    types.SizesFor(ctxt.Compiler, ctxt.GOARCH),
+		packages: map[string]*types.Package{},
+	}
+}
+
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, imp.ctxt.Dir, 0)
+}
+
+func (imp *sourceImporter) ImportFrom(path, srcDir string, _ types.ImportMode) (*types.Package, error) {
+	if abs, err := filepath.Abs(srcDir); err == nil {
+		srcDir = abs
+	}
+	bp, err := imp.ctxt.Import(path, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if bp.ImportPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	switch pkg := imp.packages[bp.ImportPath]; {
+	case pkg == &importing:
+		return nil, fmt.Errorf("import cycle through package %q", bp.ImportPath)
+	case pkg != nil:
+		return pkg, nil
+	}
+	imp.packages[bp.ImportPath] = &importing
+	defer func() {
+		if imp.packages[bp.ImportPath] == &importing {
+			delete(imp.packages, bp.ImportPath)
+		}
+	}()
+
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(imp.fset, filepath.Join(bp.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	var firstErr error
+	conf := types.Config{
+		// only the exported API of a dependency matters
+		IgnoreFuncBodies: true,
+		Importer:         imp,
+		Sizes:            imp.sizes,
+		Error: func(err error) {
+			if te, ok := err.(types.Error); firstErr == nil && (!ok || !te.Soft) {
+				firstErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, imp.fset, files, nil)
+	if firstErr != nil {
+		return nil, fmt.Errorf("type-checking package %q failed (%v)", bp.ImportPath, firstErr)
+	}
+	imp.packages[bp.ImportPath] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,777 @@
+package tag_parser
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+)
+
+// GoLoadMode controls how much of a Go package is loaded to resolve the values of tagged constants.
+type GoLoadMode int
+
+const (
+	// LoadTypes type-checks the package with go/types and evaluates every tagged constant, so values
+	// built from other constants, conversions or iota are resolved to the string the API serves.
+	LoadTypes GoLoadMode = iota
+	// LoadSyntax only parses the package and requires tagged constants to be plain string literals.
+	LoadSyntax
+)
+
+// GoTagParser parses doc tags from Go packages using go/parser. Unlike FileDocTagParser it does not
+// guess which line holds the tagged declaration; tags are attached to the ast.ValueSpec, ast.TypeSpec
+// or ast.Field whose doc comment contains them, however that declaration is laid out.
+type GoTagParser struct {
+	parsers        []DocTagParser
+	commentTrimmer LineTrimmer
+	normalizer     CommentNormalizer
+	mode           GoLoadMode
+
+	// fset holds the files of every package parsed and imported, so that positions stay comparable.
+	fset *token.FileSet
+	// mu guards importers, one per module so that imported packages are type-checked once per run.
+	mu        sync.Mutex
+	importers map[string]*sourceImporter
+}
+
+// NewGoTagParser returns a parser for the given tags. commentTrimmer is given every line of // and
+// /* */ comments, such as line_handlers.GoCommentTrimmer. normalizer may be nil to keep the comments of
+// the tagged declarations as they are, apart from the tag lines themselves.
+func NewGoTagParser(
+	parsers []DocTagParser, commentTrimmer LineTrimmer, normalizer CommentNormalizer, mode GoLoadMode,
+) *GoTagParser {
+	if normalizer == nil {
+		normalizer = NormalizerPipeline{}
+	}
+	return &GoTagParser{
+		parsers:        parsers,
+		commentTrimmer: commentTrimmer,
+		normalizer:     normalizer,
+		mode:           mode,
+		fset:           token.NewFileSet(),
+		importers:      map[string]*sourceImporter{},
+	}
+}
+
+// goPackage is a parsed (and possibly type-checked) Go package.
+type goPackage struct {
+	fset  *token.FileSet
+	files []*ast.File
+	// info is nil unless the package was loaded with LoadTypes.
+	info *types.Info
+	// typeErrors holds the errors reported while type-checking, used to explain unresolved values.
+	typeErrors []error
+	// group and version of the API package, see inferGroupVersion.
+	group   string
+	version string
+	// importPath of the package, its name if it is not in a module.
+	importPath string
+}
+
+// ParsePackage parses the non-test Go files in dir that match the current build context and returns
+// the tags found in them. Directories without Go files yield no results. Problems are reported to
+// diags and skip only the file, tag or declaration they concern, so a single run reports them all.
+func (gp *GoTagParser) ParsePackage(dir string, diags *diagnostics.Collector) []*DocTagResult {
+	pkg := gp.loadPackage(dir, diags)
+	if pkg == nil {
+		return nil
+	}
+
+	var decls []*goDecl
+	for _, file := range pkg.files {
+		decls = append(decls, gp.collectDecls(pkg, file, diags)...)
+	}
+	return gp.buildResults(pkg, decls, diags)
+}
+
+func (gp *GoTagParser) loadPackage(dir string, diags *diagnostics.Collector) *goPackage {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		diags.Errorf(diagnostics.Position{File: dir}, "%v", err)
+		return nil
+	}
+
+	pkg := &goPackage{fset: gp.fset}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		file, err := parser.ParseFile(
+			pkg.fset, filepath.Join(dir, name), nil,
+			parser.ParseComments|parser.SkipObjectResolution|parser.AllErrors,
+		)
+		if err != nil {
+			reportParseError(diags, filepath.Join(dir, name), err)
+			continue
+		}
+		if len(pkg.files) > 0 && pkg.files[0].Name.Name != file.Name.Name {
+			diags.Errorf(
+				position(pkg.fset.Position(file.Name.Pos())),
+				"found packages %s and %s in the same directory, skipping this file",
+				pkg.files[0].Name.Name, file.Name.Name,
+			)
+			continue
+		}
+		pkg.files = append(pkg.files, file)
+	}
+	if len(pkg.files) == 0 {
+		return nil
+	}
+	sort.Slice(pkg.files, func(i, j int) bool {
+		return pkg.fset.File(pkg.files[i].Pos()).Name() < pkg.fset.File(pkg.files[j].Pos()).Name()
+	})
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		diags.Errorf(diagnostics.Position{File: dir}, "%v", err)
+		return nil
+	}
+	pkg.importPath = importPath(absDir, pkg.files[0].Name.Name)
+
+	// Type-checking loads every import from source, skip it for packages without tags.
+	if gp.mode == LoadTypes && gp.hasTags(pkg) {
+		gp.typeCheck(pkg, absDir)
+	}
+	pkg.group, pkg.version = inferGroupVersion(pkg)
+	return pkg
+}
+
+// hasTags returns true if a comment in the package contains a tag.
+func (gp *GoTagParser) hasTags(pkg *goPackage) bool {
+	for _, file := range pkg.files {
+		for _, cg := range file.Comments {
+			for _, line := range gp.commentLines(pkg.fset, cg) {
+				if slices.ContainsFunc(gp.parsers, func(p DocTagParser) bool { return p.Matches(line.text) }) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// typeCheck type-checks the package in dir, resolving its imports in the module that contains it.
+func (gp *GoTagParser) typeCheck(pkg *goPackage, dir string) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+
+	modDir := moduleDir(dir)
+	if modDir == "" {
+		modDir = dir
+	}
+	imp := gp.importers[modDir]
+	if imp == nil {
+		imp = newSourceImporter(gp.fset, modDir)
+		gp.importers[modDir] = imp
+	}
+
+	pkg.info = &types.Info{Defs: map[*ast.Ident]types.Object{}, Types: map[ast.Expr]types.TypeAndValue{}}
+	conf := types.Config{
+		Importer: imp,
+		// Keep going on errors, constants that do not depend on the broken code still resolve.
+		Error: func(err error) { pkg.typeErrors = append(pkg.typeErrors, err) },
+	}
+	_, _ = conf.Check(pkg.importPath, pkg.fset, pkg.files, pkg.info)
+}
+
+// moduleDir returns the directory of the go.mod of the module containing dir, "" if there is none.
+func moduleDir(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// importPath returns the import path of the package in dir, derived from the module path in the
+// nearest go.mod. Packages outside of a module are identified by their name.
+func importPath(dir, name string) string {
+	modDir := moduleDir(dir)
+	if modDir == "" {
+		return name
+	}
+	data, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+	if err != nil {
+		return name
+	}
+	m := reModulePath.FindSubmatch(data)
+	if m == nil {
+		return name
+	}
+	rel, err := filepath.Rel(modDir, dir)
+	if err != nil || rel == "." {
+		return strings.Trim(string(m[1]), `"`)
+	}
+	return strings.Trim(string(m[1]), `"`) + "/" + filepath.ToSlash(rel)
+}
+
+var reModulePath = regexp.MustCompile(`(?m)^\s*module\s+(\S+)`)
+
+// reportParseError reports every syntax error of a file that go/parser rejected.
+func reportParseError(diags *diagnostics.Collector, filename string, err error) {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		diags.Errorf(diagnostics.Position{File: filename}, "%v", err)
+		return
+	}
+	// go/parser reports follow-up errors after the first one on a line, those are noise
+	list.RemoveMultiples()
+	for _, e := range list {
+		diags.Errorf(position(e.Pos), "%s", e.Msg)
+	}
+}
+
+var reAPIVersion = regexp.MustCompile(`^v\d+((alpha|beta)\d+)?$`)
+
+// inferGroupVersion determines the API group and version served by a package from the kubebuilder
+// conventions: the +groupName and +versionName markers, the schema.GroupVersion declared in
+// groupversion_info.go, and finally a package named after the version (e.g. v1alpha1).
+func inferGroupVersion(pkg *goPackage) (string, string) {
+	var group, version string
+	for _, file := range pkg.files {
+		for _, cg := range file.Comments {
+			for _, c := range cg.List {
+				text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+				if v, ok := strings.CutPrefix(text, "+groupName="); ok && group == "" {
+					group = strings.TrimSpace(v)
+				}
+				if v, ok := strings.CutPrefix(text, "+versionName="); ok && version == "" {
+					version = strings.TrimSpace(v)
+				}
+			}
+		}
+	}
+
+	for _, file := range pkg.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			if sel, ok := lit.Type.(*ast.SelectorExpr); !ok || sel.Sel.Name != "GroupVersion" {
+				return true
+			}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, _ := kv.Key.(*ast.Ident)
+				value, ok := stringValue(pkg, kv.Value)
+				switch {
+				case !ok || key == nil:
+				case key.Name == "Group" && group == "":
+					group = value
+				case key.Name == "Version" && version == "":
+					version = value
+				}
+			}
+			return false
+		})
+	}
+
+	if name := pkg.files[0].Name.Name; version == "" && reAPIVersion.MatchString(name) {
+		version = name
+	}
+	return group, version
+}
+
+// stringValue returns the value of a string literal, or of any constant string expression if the
+// package was type-checked.
+func stringValue(pkg *goPackage, expr ast.Expr) (string, bool) {
+	if pkg.info != nil {
+		if tv, ok := pkg.info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value), true
+		}
+	}
+	return literalValue(expr)
+}
+
+// goDeclKind is the kind of declaration a doc comment belongs to.
+type goDeclKind int
+
+const (
+	goConst goDeclKind = iota
+	goVar
+	goType
+	goField
+)
+
+// goDecl is a single declared name together with its doc comment and the tags in it.
+type goDecl struct {
+	kind goDeclKind
+	name *ast.Ident
+	// value is the expression assigned to a const or var, nil for implicitly repeated constants.
+	value ast.Expr
+	// spec is the ast.ValueSpec of a const or var.
+	spec ast.Node
+	// typeName is the name of the declared type of a const as written in the source, used when the
+	// package is not type-checked, or the name of the struct a field belongs to.
+	typeName string
+	// field is the struct field a goField name belongs to.
+	field *ast.Field
+	doc   *ast.CommentGroup
+	lines []commentLine
+	tags  []goTag
+}
+
+// commentLine is a line of a comment without comment markers, pos is where its text starts.
+type commentLine struct {
+	text string
+	pos  token.Position
+}
+
+// goTag is a tag found in a doc comment, line is its index in goDecl.lines or -1 if the tag is
+// inherited from the type of a constant.
+type goTag struct {
+	parser DocTagParser
+	tag    DocTag
+	line   int
+}
+
+// hasTag returns true if the declaration is tagged with the given DocTagType itself.
+func (d *goDecl) hasTag(t DocTagType) bool {
+	for _, tag := range d.tags {
+		if tag.parser.Type() == t {
+			return true
+		}
+	}
+	return false
+}
+
+// collectDecls returns every const, var, type and struct field declared in the file. Tags that do
+// not parse are reported and left out.
+func (gp *GoTagParser) collectDecls(pkg *goPackage, file *ast.File, diags *diagnostics.Collector) []*goDecl {
+	var decls []*goDecl
+	attached := map[*ast.CommentGroup]bool{}
+
+	// add records a declaration for each name, proto holds what the names have in common.
+	add := func(proto goDecl, names []*ast.Ident, values []ast.Expr) {
+		doc := proto.doc
+		var lines []commentLine
+		var tags []goTag
+		if doc != nil {
+			attached[doc] = true
+			lines = gp.commentLines(pkg.fset, doc)
+			for i, line := range lines {
+				for _, p := range gp.parsers {
+					if !p.Matches(line.text) {
+						continue
+					}
+					tag, err := p.ParseTag(line.text)
+					if err != nil {
+						diags.Errorf(position(line.pos), "%v", err)
+						continue
+					}
+					tags = append(tags, goTag{parser: p, tag: tag, line: i})
+				}
+			}
+		}
+		for i, name := range names {
+			d := proto
+			d.name, d.lines, d.tags = name, lines, tags
+			if i < len(values) {
+				d.value = values[i]
+			}
+			decls = append(decls, &d)
+		}
+	}
+
+	// Struct types are visited after the TypeSpec that names them, anonymous structs have no name.
+	structNames := map[*ast.StructType]string{}
+	visit := func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			// declarations local to a function are not part of the package API
+			return false
+		case *ast.GenDecl:
+			// Constants without a type and value repeat the previous ones in the group.
+			var prevType string
+			for _, spec := range node.Specs {
+				doc := specDoc(node, spec)
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					kind := goVar
+					if node.Tok == token.CONST {
+						kind = goConst
+						if ident, ok := s.Type.(*ast.Ident); ok {
+							prevType = ident.Name
+						} else if s.Type != nil || len(s.Values) > 0 {
+							prevType = ""
+						}
+					}
+					add(goDecl{kind: kind, doc: doc, typeName: prevType, spec: s}, s.Names, s.Values)
+				case *ast.TypeSpec:
+					if st, ok := s.Type.(*ast.StructType); ok {
+						structNames[st] = s.Name.Name
+					}
+					add(goDecl{kind: goType, doc: doc}, []*ast.Ident{s.Name}, nil)
+				}
+			}
+		case *ast.StructType:
+			for _, field := range node.Fields.List {
+				names := field.Names
+				if len(names) == 0 {
+					// an embedded field is named after its type
+					names = []*ast.Ident{embeddedName(field.Type)}
+				}
+				add(goDecl{kind: goField, doc: field.Doc, field: field, typeName: structNames[node]}, names, nil)
+			}
+		}
+		return true
+	}
+	// Only the package level declarations, function bodies are skipped.
+	for _, decl := range file.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok {
+			ast.Inspect(gd, visit)
+		}
+	}
+
+	// Tags in comments that do not document a declaration would silently be dropped, report them.
+	inFunc := func(cg *ast.CommentGroup) bool {
+		return slices.ContainsFunc(file.Decls, func(d ast.Decl) bool {
+			fd, ok := d.(*ast.FuncDecl)
+			return ok && fd.Body != nil && fd.Body.Pos() < cg.Pos() && cg.End() < fd.Body.End()
+		})
+	}
+	for _, cg := range file.Comments {
+		if attached[cg] {
+			continue
+		}
+		for _, line := range gp.commentLines(pkg.fset, cg) {
+			if !slices.ContainsFunc(gp.parsers, func(p DocTagParser) bool { return p.Matches(line.text) }) {
+				continue
+			}
+			if inFunc(cg) {
+				diags.Errorf(position(line.pos), "tag is inside a function, only package level declarations are documented")
+				continue
+			}
+			diags.Errorf(position(line.pos), "tag is not attached to a const, type or field declaration")
+		}
+	}
+
+	return decls
+}
+
+// buildResults turns the declarations of a package into tag results. Tags on a named type apply to
+// every constant of that type, unless the constant carries a tag of the same DocTagType itself.
+func (gp *GoTagParser) buildResults(pkg *goPackage, decls []*goDecl, diags *diagnostics.Collector) []*DocTagResult {
+	typeTags := map[string][]goTag{}
+	for _, d := range decls {
+		if d.kind == goType && len(d.tags) > 0 {
+			typeTags[d.name.Name] = d.tags
+		}
+	}
+
+	var results []*DocTagResult
+	inherited := map[string]bool{}
+	for _, d := range decls {
+		if d.kind == goType {
+			continue
+		}
+
+		tags := d.tags
+		if d.kind == goConst {
+			if typeName := gp.constTypeName(pkg, d); typeName != "" {
+				for _, tag := range typeTags[typeName] {
+					inherited[typeName] = true
+					if !d.hasTag(tag.parser.Type()) {
+						tags = append(tags, goTag{parser: tag.parser, tag: tag.tag, line: -1})
+					}
+				}
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+
+		value, valueType := d.name.Name, "string"
+		switch d.kind {
+		case goConst, goVar:
+			v, err := gp.resolveValue(pkg, d)
+			if err != nil {
+				diags.Errorf(position(pkg.fset.Position(d.name.Pos())), "%v", err)
+				continue
+			}
+			value = v
+		case goField:
+			v, err := jsonFieldName(d.name.Name, d.field)
+			if err != nil {
+				diags.Errorf(position(pkg.fset.Position(d.name.Pos())), "%v", err)
+				continue
+			}
+			value, valueType = v, types.ExprString(d.field.Type)
+		}
+
+		comment := strings.Join(trimBlankLines(gp.normalizer.Normalize(d.description(), d.name.Name)), "\n")
+		for _, tag := range tags {
+			results = append(results, &DocTagResult{
+				Comment: comment,
+				Variable: map[string]string{
+					"const": d.name.Name,
+					"value": value,
+					"type":  valueType,
+				},
+				Tag:     tag.tag,
+				Type:    tag.parser.Type(),
+				Group:   pkg.group,
+				Version: pkg.version,
+				Source:  gp.source(pkg, d),
+			})
+		}
+	}
+
+	for _, d := range decls {
+		if d.kind == goType && len(d.tags) > 0 && !inherited[d.name.Name] {
+			diags.Errorf(
+				position(pkg.fset.Position(d.name.Pos())),
+				"type %s is tagged but the package declares no constants of that type", d.name.Name,
+			)
+		}
+	}
+
+	return results
+}
+
+// source locates a declaration and identifies it by its package qualified name.
+func (gp *GoTagParser) source(pkg *goPackage, d *goDecl) Source {
+	p := pkg.fset.Position(d.name.Pos())
+	src := Source{
+		File:        p.Filename,
+		Line:        p.Line,
+		Column:      p.Column,
+		ImportPath:  pkg.importPath,
+		PackageName: pkg.files[0].Name.Name,
+		Ident:       pkg.files[0].Name.Name + "." + d.name.Name,
+	}
+	switch d.kind {
+	case goConst, goVar:
+		src.Kind = "const"
+		if d.kind == goVar {
+			src.Kind = "var"
+		}
+		src.TypeName = d.typeName
+		if pkg.info != nil {
+			src.TypeName = ""
+			// untyped constants have no type worth showing
+			if obj := pkg.info.Defs[d.name]; obj != nil && !isUntyped(obj.Type()) {
+				src.TypeName = types.TypeString(obj.Type(), types.RelativeTo(obj.Pkg()))
+			}
+		}
+	case goField:
+		src.Kind = "field"
+		src.TypeName = d.typeName
+		if d.typeName != "" {
+			src.Ident = pkg.files[0].Name.Name + "." + d.typeName + "." + d.name.Name
+		}
+	}
+	return src
+}
+
+func isUntyped(t types.Type) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Info()&types.IsUntyped != 0
+}
+
+// constTypeName returns the name of the package-local named type of a constant, or "" if it has none.
+func (gp *GoTagParser) constTypeName(pkg *goPackage, d *goDecl) string {
+	if pkg.info == nil {
+		return d.typeName
+	}
+	obj, ok := pkg.info.Defs[d.name].(*types.Const)
+	if !ok {
+		return ""
+	}
+	named, ok := types.Unalias(obj.Type()).(*types.Named)
+	if !ok || named.Obj().Pkg() != obj.Pkg() {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+// jsonFieldName returns the name a struct field is serialized with, following encoding/json: the
+// name from the json struct tag, or the Go field name if the tag does not set one. Unexported fields
+// are not serialized at all.
+func jsonFieldName(name string, field *ast.Field) (string, error) {
+	if len(field.Names) > 0 && !ast.IsExported(name) {
+		return "", fmt.Errorf("field %s is tagged but not serialized, it is unexported", name)
+	}
+	jsonName := name
+	if field.Tag != nil {
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return "", fmt.Errorf("invalid struct tag on field %s: %w", name, err)
+		}
+		jsonTag := reflect.StructTag(tag).Get("json")
+		if jsonTag == "-" {
+			return "", fmt.Errorf("field %s is tagged but not serialized (json:\"-\")", name)
+		}
+		if n, _, _ := strings.Cut(jsonTag, ","); n != "" {
+			return n, nil
+		}
+	}
+	if len(field.Names) == 0 {
+		return "", fmt.Errorf(
+			"embedded field %s is inlined in JSON, give it a name in its json struct tag or tag its fields", name,
+		)
+	}
+	return jsonName, nil
+}
+
+// embeddedName returns the identifier an embedded field is named after, the name of its type without
+// pointer, package and type arguments.
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	case *ast.Ident:
+		return e
+	}
+	return &ast.Ident{Name: types.ExprString(expr), NamePos: expr.Pos()}
+}
+
+// description returns the comment lines of a declaration without its tag lines.
+func (d *goDecl) description() []string {
+	var lines []string
+	for i, line := range d.lines {
+		if !slices.ContainsFunc(d.tags, func(t goTag) bool { return t.line == i }) {
+			lines = append(lines, line.text)
+		}
+	}
+	return lines
+}
+
+// resolveValue returns the string value of a tagged const or var.
+func (gp *GoTagParser) resolveValue(pkg *goPackage, d *goDecl) (string, error) {
+	name, value := d.name, d.value
+	if pkg.info == nil {
+		if v, ok := literalValue(value); ok {
+			return v, nil
+		}
+		return "", fmt.Errorf(
+			"the value of %s is not a string literal, load the package with types to resolve it", name.Name,
+		)
+	}
+
+	switch obj := pkg.info.Defs[name].(type) {
+	case *types.Const:
+		switch obj.Val().Kind() {
+		case constant.String:
+			return constant.StringVal(obj.Val()), nil
+		case constant.Unknown:
+			return "", unresolvedError(pkg, d)
+		default:
+			return "", fmt.Errorf("constant %s is not a string but %s", name.Name, obj.Val().Kind())
+		}
+	case *types.Var:
+		if v, ok := literalValue(value); ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("variable %s is not initialized with a string literal, use a constant instead", name.Name)
+	default:
+		return "", unresolvedError(pkg, d)
+	}
+}
+
+// unresolvedError explains why the value of d is unknown with the first type error inside its
+// declaration, if there is one.
+func unresolvedError(pkg *goPackage, d *goDecl) error {
+	err := fmt.Errorf("cannot resolve the value of %s", d.name.Name)
+	for _, typeErr := range pkg.typeErrors {
+		var te types.Error
+		if errors.As(typeErr, &te) && d.spec != nil && d.spec.Pos() <= te.Pos && te.Pos < d.spec.End() {
+			return fmt.Errorf("%w: %v", err, typeErr)
+		}
+	}
+	return err
+}
+
+// commentLines returns every line in the comment group with comment markers removed, together with
+// the position of its text. The lines of // and /* */ comments are both trimmed by the commentTrimmer,
+// or by the one it returns for the comment if it is a CommentTrimmer.
+func (gp *GoTagParser) commentLines(fset *token.FileSet, cg *ast.CommentGroup) []commentLine {
+	var lines []commentLine
+	for _, c := range cg.List {
+		trimmer := gp.commentTrimmer
+		if ct, ok := trimmer.(CommentTrimmer); ok {
+			trimmer = ct.ForComment(c.Text)
+		}
+		start := fset.Position(c.Slash)
+		for i, raw := range strings.Split(c.Text, "\n") {
+			pos := start
+			if i > 0 {
+				pos.Line += i
+				pos.Column = 1
+			}
+			text := trimmer.Trim(raw)
+			if text != "" {
+				pos.Column += max(strings.Index(raw, text), 0)
+			}
+			lines = append(lines, commentLine{text: text, pos: pos})
+		}
+	}
+	return lines
+}
+
+// specDoc returns the doc comment of a spec. A declaration without parentheses keeps its doc
+// comment on the GenDecl rather than on the spec itself.
+func specDoc(decl *ast.GenDecl, spec ast.Spec) *ast.CommentGroup {
+	var doc *ast.CommentGroup
+	switch s := spec.(type) {
+	case *ast.ValueSpec:
+		doc = s.Doc
+	case *ast.TypeSpec:
+		doc = s.Doc
+	}
+	if doc == nil && !decl.Lparen.IsValid() {
+		doc = decl.Doc
+	}
+	return doc
+}
+
+// literalValue returns the unquoted value of a string literal.
+func literalValue(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	v, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+// position converts a token.Position to the position of a diagnostic.
+func position(p token.Position) diagnostics.Position {
+	return diagnostics.Position{File: p.Filename, Line: p.Line, Column: p.Column}
+}
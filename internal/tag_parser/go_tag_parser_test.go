@@ -0,0 +1,459 @@
+package tag_parser_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	lhs "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/line_handlers"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+)
+
+// goPackageTest parses a package made of the source src, put in api/types.go of a module, the lines of
+// which the results and diagnostics are compared by.
+type goPackageTest struct {
+	name string
+	mode tp.GoLoadMode
+	src  string
+	// want holds a line per result: <line> <tag type> <const>=<value> <type>, followed by " | <comment>"
+	// if the result has a comment, whose line breaks are written as "\n".
+	want []string
+	// wantDiags holds a line per diagnostic: <line>: <part of its message>.
+	wantDiags []string
+}
+
+func runGoPackageTests(t *testing.T, tests []goPackageTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/op\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Mkdir(filepath.Join(dir, "api"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			filename := filepath.Join(dir, "api", "types.go")
+			if err := os.WriteFile(filename, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			gp := tp.NewGoTagParser(
+				[]tp.DocTagParser{tps.ConditionTagParser{}, tps.ReasonTagParser{}}, lhs.GoCommentTrimmer{}, nil, tt.mode,
+			)
+			diags := diagnostics.NewCollector()
+			results := gp.ParsePackage(filepath.Join(dir, "api"), diags)
+
+			var got []string
+			for _, r := range results {
+				if r.Source.File != filename {
+					t.Errorf("result in %s, want %s", r.Source.File, filename)
+				}
+				line := fmt.Sprintf(
+					"%d %s %s=%s %s", r.Source.Line, r.Type, r.Variable["const"], r.Variable["value"], r.Variable["type"],
+				)
+				if r.Comment != "" {
+					line += " | " + strings.ReplaceAll(r.Comment, "\n", `\n`)
+				}
+				got = append(got, line)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("results\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+
+			gotDiags := diags.Diagnostics()
+			if len(gotDiags) != len(tt.wantDiags) {
+				t.Fatalf("diagnostics %v, want %v", gotDiags, tt.wantDiags)
+			}
+			for i, d := range gotDiags {
+				line, msg, _ := strings.Cut(tt.wantDiags[i], ": ")
+				if fmt.Sprint(d.Line) != line || d.File != filename || !strings.Contains(d.Message, msg) {
+					t.Errorf("diagnostic %s:%d: %s, want line %s: %s", d.File, d.Line, d.Message, line, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestGoTagParserDeclarations(t *testing.T) {
+	runGoPackageTests(t, []goPackageTest{
+		{
+			name: "const and var specs spanning lines",
+			src: `package api
+
+type ConditionType string
+
+const (
+	// Ready is true when the cluster is ready.
+	// +cty:condition:for=Cluster
+	Ready ConditionType =
+		"Ready"
+
+	// Synced tells whether the spec was applied.
+	// +cty:condition:for=Cluster
+	Synced, Degraded ConditionType = "Synced", "Degraded"
+)
+
+// +cty:condition:for=Cluster
+var Progressing = "Progressing"
+`,
+			want: []string{
+				"8 condition Ready=Ready string | Ready is true when the cluster is ready.",
+				"13 condition Synced=Synced string | Synced tells whether the spec was applied.",
+				"13 condition Degraded=Degraded string | Synced tells whether the spec was applied.",
+				"17 condition Progressing=Progressing string",
+			},
+		},
+		{
+			name: "block comments",
+			src: `package api
+
+const (
+	/*
+	 * Ready is true when the cluster is ready.
+	 *  - status = true: ready
+	 * +cty:condition:for=Cluster
+	 */
+	Ready = "Ready"
+
+	/* Synced is set when
+	   * the spec was applied
+	   +cty:condition:for=Cluster */
+	Synced = "Synced"
+)
+`,
+			want: []string{
+				`9 condition Ready=Ready string | Ready is true when the cluster is ready.\n- status = true: ready`,
+				`14 condition Synced=Synced string | Synced is set when\n* the spec was applied`,
+			},
+		},
+		{
+			name: "values built from constants and conversions",
+			src: `package api
+
+type ConditionType string
+
+const prefix = "Cluster"
+
+const (
+	// +cty:condition:for=Cluster
+	PrefixedReady ConditionType = prefix + "Ready"
+	// +cty:condition:for=Cluster
+	Converted = ConditionType("Converted")
+	// +cty:condition:for=Cluster
+	Plain = string(Converted) + "Plain"
+)
+`,
+			want: []string{
+				"9 condition PrefixedReady=ClusterReady string",
+				"11 condition Converted=Converted string",
+				"13 condition Plain=ConvertedPlain string",
+			},
+		},
+		{
+			name: "values that are not strings",
+			src: `package api
+
+type Phase int
+
+const (
+	// +cty:condition:for=Cluster
+	Started Phase = iota
+	// +cty:condition:for=Cluster
+	Broken = Undefined + "x"
+	// +cty:condition:for=Cluster
+	Fine = "Fine"
+)
+
+// +cty:condition:for=Cluster
+var Computed = Fine + "x"
+`,
+			want: []string{"11 condition Fine=Fine string"},
+			wantDiags: []string{
+				"7: constant Started is not a string but Int",
+				// the type error inside the declaration explains it
+				"9: types.go:9:11: undefined: Undefined",
+				"15: variable Computed is not initialized with a string literal",
+			},
+		},
+		{
+			name: "only literals without type-checking",
+			mode: tp.LoadSyntax,
+			src: `package api
+
+const prefix = "Cluster"
+
+const (
+	// +cty:condition:for=Cluster
+	Ready = "Ready"
+	// +cty:condition:for=Cluster
+	PrefixedReady = prefix + "Ready"
+)
+`,
+			want:      []string{"7 condition Ready=Ready string"},
+			wantDiags: []string{"9: the value of PrefixedReady is not a string literal"},
+		},
+		{
+			name: "tags that document no package level declaration",
+			src: `package api
+
+// +cty:condition:for=Cluster
+
+const Ready = "Ready"
+
+func f() {
+	// +cty:condition:for=Cluster
+	const Local = "Local"
+	_ = func() {
+		// +cty:condition:for=Cluster
+		var nested = "Nested"
+		_ = nested
+	}
+}
+`,
+			wantDiags: []string{
+				"3: tag is not attached to a const, type or field declaration",
+				"8: tag is inside a function",
+				"11: tag is inside a function",
+			},
+		},
+		{
+			name: "invalid tags are reported where they are",
+			src: `package api
+
+const (
+	// Ready is true.
+	// +cty:condition:for=Cluster,polarity=up
+	Ready = "Ready"
+)
+`,
+			wantDiags: []string{"5: invalid polarity"},
+		},
+	})
+}
+
+func TestGoTagParserTypeTags(t *testing.T) {
+	const src = `package api
+
+// ReasonType is the reason of the Ready condition.
+// +cty:reason:for=Cluster/Ready
+type ReasonType string
+
+const (
+	// Starting is the reason while the cluster starts.
+	Starting ReasonType = "Starting"
+	// Failed overrides the condition of its type.
+	// +cty:reason:for=Cluster/Synced
+	Failed ReasonType = "Failed"
+	Untagged string = "Untagged"
+)
+
+// +cty:condition:for=Cluster
+type ConditionType string
+
+// Ready is also a reason of itself.
+// +cty:reason:for=Cluster/Ready
+const Ready ConditionType = "Ready"
+`
+	runGoPackageTests(t, []goPackageTest{
+		{
+			name: "constants inherit the tags of their type unless they override them",
+			src:  src,
+			want: []string{
+				"9 reason Starting=Starting string | Starting is the reason while the cluster starts.",
+				"12 reason Failed=Failed string | Failed overrides the condition of its type.",
+				"21 reason Ready=Ready string | Ready is also a reason of itself.",
+				"21 condition Ready=Ready string | Ready is also a reason of itself.",
+			},
+		},
+		{
+			name: "the type of a constant is taken from the source without type-checking",
+			mode: tp.LoadSyntax,
+			src:  src,
+			want: []string{
+				"9 reason Starting=Starting string | Starting is the reason while the cluster starts.",
+				"12 reason Failed=Failed string | Failed overrides the condition of its type.",
+				"21 reason Ready=Ready string | Ready is also a reason of itself.",
+				"21 condition Ready=Ready string | Ready is also a reason of itself.",
+			},
+		},
+		{
+			name: "untyped constants do not inherit the tags",
+			src: `package api
+
+// +cty:condition:for=Cluster
+type ConditionType string
+
+const (
+	Ready ConditionType = "Ready"
+	Synced              = "Synced"
+	Other               = ConditionType("Other")
+)
+`,
+			want: []string{
+				"7 condition Ready=Ready string",
+				"9 condition Other=Other string",
+			},
+		},
+		{
+			name: "a tagged type without constants",
+			src: `package api
+
+// +cty:condition:for=Cluster
+type ConditionType string
+
+const Ready = "Ready"
+`,
+			wantDiags: []string{"4: type ConditionType is tagged but the package declares no constants of that type"},
+		},
+	})
+}
+
+func TestGoTagParserFields(t *testing.T) {
+	runGoPackageTests(t, []goPackageTest{
+		{
+			name: "fields are documented by their JSON name",
+			src: `package api
+
+type ClusterStatus struct {
+	// Ready is true when the cluster is ready.
+	// +cty:condition:for=Cluster
+	Ready bool ` + "`json:\"ready,omitempty\"`" + `
+	// +cty:condition:for=Cluster
+	Synced *bool ` + "`json:\",omitempty\"`" + `
+	// +cty:condition:for=Cluster
+	Phase, Health string
+}
+`,
+			want: []string{
+				"6 condition Ready=ready bool | Ready is true when the cluster is ready.",
+				"8 condition Synced=Synced *bool",
+				"10 condition Phase=Phase string",
+				"10 condition Health=Health string",
+			},
+		},
+		{
+			name: "embedded fields are documented by the name of their JSON object",
+			src: `package api
+
+import "time"
+
+type Inner struct{}
+
+type Generic[T any] struct{}
+
+type ClusterStatus struct {
+	// +cty:condition:for=Cluster
+	*Inner ` + "`json:\"inner\"`" + `
+	// +cty:condition:for=Cluster
+	time.Time ` + "`json:\"time,omitempty\"`" + `
+	// +cty:condition:for=Cluster
+	Generic[string] ` + "`json:\"generic\"`" + `
+}
+`,
+			want: []string{
+				"11 condition Inner=inner *Inner",
+				"13 condition Time=time time.Time",
+				"15 condition Generic=generic Generic[string]",
+			},
+		},
+		{
+			name: "fields that are not serialized under a name of their own",
+			src: `package api
+
+type Inner struct{}
+
+type ClusterStatus struct {
+	// +cty:condition:for=Cluster
+	Inner
+	// +cty:condition:for=Cluster
+	Hidden string ` + "`json:\"-\"`" + `
+	// +cty:condition:for=Cluster
+	internal string ` + "`json:\"internal\"`" + `
+}
+`,
+			wantDiags: []string{
+				"7: embedded field Inner is inlined in JSON",
+				`9: field Hidden is tagged but not serialized (json:"-")`,
+				"11: field internal is tagged but not serialized, it is unexported",
+			},
+		},
+	})
+}
+
+func TestGoTagParserGroupVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		wantGroup   string
+		wantVersion string
+	}{
+		{
+			name: "markers",
+			src: `// +groupName=zeebe.example.com
+// +versionName=v2
+package v1
+
+// +cty:condition:for=Cluster
+const Ready = "Ready"
+`,
+			wantGroup:   "zeebe.example.com",
+			wantVersion: "v2",
+		},
+		{
+			name: "groupName marker and version package",
+			src: `// +kubebuilder:object:generate=true
+// +groupName=zeebe.example.com
+package v1alpha1
+
+// +cty:condition:for=Cluster
+const Ready = "Ready"
+`,
+			wantGroup:   "zeebe.example.com",
+			wantVersion: "v1alpha1",
+		},
+		{
+			name: "GroupVersion declaration",
+			src: `package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var GroupVersion = schema.GroupVersion{Group: "zeebe.example.com", Version: "v1beta1"}
+
+// +cty:condition:for=Cluster
+const Ready = "Ready"
+`,
+			wantGroup:   "zeebe.example.com",
+			wantVersion: "v1beta1",
+		},
+		{
+			name: "nothing to infer from",
+			src: `package api
+
+// +cty:condition:for=Cluster
+const Ready = "Ready"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			gp := tp.NewGoTagParser([]tp.DocTagParser{tps.ConditionTagParser{}}, lhs.GoCommentTrimmer{}, nil, tp.LoadSyntax)
+			diags := diagnostics.NewCollector()
+			results := gp.ParsePackage(dir, diags)
+			if len(results) != 1 || diags.HasErrors() {
+				t.Fatalf("results %v, diagnostics %v, want a result", results, diags.Diagnostics())
+			}
+			if r := results[0]; r.Group != tt.wantGroup || r.Version != tt.wantVersion {
+				t.Errorf("group %q version %q, want %q %q", r.Group, r.Version, tt.wantGroup, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -18,3 +18,45 @@ func (GoLineCommentBulletPointMatcher) Matches(line string) bool {
 		strings.HasPrefix(s, "//  *") ||
 		strings.HasPrefix(s, "// *")
 }
+
+// GoBlockCommentMatcher matches the lines of /* */ comments: the line opening the comment, and the
+// continuation and closing lines as long as they start with '*'.
+type GoBlockCommentMatcher struct{}
+
+func (GoBlockCommentMatcher) Matches(line string) bool {
+	s := strings.TrimSpace(line)
+	return strings.HasPrefix(s, "/*") ||
+		s == "*" ||
+		strings.HasPrefix(s, "* ") ||
+		strings.HasPrefix(s, "*\t") ||
+		strings.HasPrefix(s, "*/")
+}
+
+// GoCommentMatcher matches both // and /* */ comment lines.
+type GoCommentMatcher struct{}
+
+func (GoCommentMatcher) Matches(line string) bool {
+	return GoLineCommentMatcher{}.Matches(line) || GoBlockCommentMatcher{}.Matches(line)
+}
+
+// BulletPointMatcher matches comment lines that were already trimmed and start with a bullet.
+type BulletPointMatcher struct{}
+
+func (BulletPointMatcher) Matches(line string) bool {
+	s := strings.TrimSpace(line)
+	return strings.HasPrefix(s, "- ") || strings.HasPrefix(s, "* ") || strings.HasPrefix(s, "• ")
+}
+
+// PythonCommentMatcher matches # comment lines.
+type PythonCommentMatcher struct{}
+
+func (PythonCommentMatcher) Matches(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// RustCommentMatcher matches // comment lines, including /// and //! doc comments.
+type RustCommentMatcher struct{}
+
+func (RustCommentMatcher) Matches(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "//")
+}
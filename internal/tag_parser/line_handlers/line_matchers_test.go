@@ -0,0 +1,104 @@
+package line_handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+	tps "github.com/sourcehawk/cty-generator-addons/internal/tag_parser/tag_parsers"
+)
+
+func TestGoCommentMatchers(t *testing.T) {
+	tests := []struct {
+		line        string
+		lineComment bool
+		block       bool
+	}{
+		{line: "// Ready is true", lineComment: true},
+		{line: "\t//", lineComment: true},
+		{line: "/* Ready is true */", block: true},
+		{line: "\t/*", block: true},
+		{line: " * Ready is true", block: true},
+		{line: " *", block: true},
+		{line: "\t*\tReady", block: true},
+		{line: " */", block: true},
+		{line: "*bold* text"},
+		{line: "Ready = 2 * 3"},
+		{line: `Ready ConditionType = "Ready"`},
+		{line: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := (GoLineCommentMatcher{}).Matches(tt.line); got != tt.lineComment {
+				t.Errorf("GoLineCommentMatcher = %v, want %v", got, tt.lineComment)
+			}
+			if got := (GoBlockCommentMatcher{}).Matches(tt.line); got != tt.block {
+				t.Errorf("GoBlockCommentMatcher = %v, want %v", got, tt.block)
+			}
+			if got := (GoCommentMatcher{}).Matches(tt.line); got != (tt.lineComment || tt.block) {
+				t.Errorf("GoCommentMatcher = %v, want %v", got, tt.lineComment || tt.block)
+			}
+		})
+	}
+}
+
+// constExtractor reads the name of the const on the first line, enough for the tests of the matchers.
+type constExtractor struct{}
+
+func (constExtractor) Extract(lines []string) (map[string]string, error) {
+	name := strings.TrimSpace(lines[0])
+	return map[string]string{"const": name, "value": name, "type": "string"}, nil
+}
+
+// The block comment handlers plug into FileDocTagParser, and document a declaration like the
+// equivalent // comment.
+func TestFileTagParserBlockComments(t *testing.T) {
+	const src = `package api
+
+// Ready is true when the cluster is ready.
+//   - status = true: ready
+// +cty:condition:for=Cluster
+Ready
+
+/*
+ * Ready is true when the cluster is ready.
+ *   - status = true: ready
+ * +cty:condition:for=Cluster
+ */
+Ready
+
+/* Ready is true when the cluster is ready. +cty:condition:for=Cluster */
+Ready
+`
+	filename := filepath.Join(t.TempDir(), "api.go")
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parser := tp.NewFileTagParser(
+		[]tp.DocTagParser{tps.ConditionTagParser{}},
+		GoCommentMatcher{}, GoCommentTrimmer{}, nil, BulletPointMatcher{}, constExtractor{},
+	)
+	diags := diagnostics.NewCollector()
+	results := parser.ParseTags(filename, diags)
+	if d := diags.Diagnostics(); len(d) > 0 {
+		t.Fatalf("diagnostics: %v", d)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	const want = "Ready is true when the cluster is ready.\n- status = true: ready"
+	for i, r := range results[:2] {
+		if r.Comment != want {
+			t.Errorf("result %d: comment %q, want %q", i, r.Comment, want)
+		}
+		if r.Variable["const"] != "Ready" {
+			t.Errorf("result %d: const %q, want Ready", i, r.Variable["const"])
+		}
+	}
+	if tag, ok := results[2].Tag.(tps.ConditionTag); !ok || len(tag.CRDs) != 1 || tag.CRDs[0].Kind != "Cluster" {
+		t.Errorf("single line block comment: tag %+v", results[2].Tag)
+	}
+}
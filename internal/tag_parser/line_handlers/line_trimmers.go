@@ -1,6 +1,10 @@
 package line_handlers
 
-import "strings"
+import (
+	"strings"
+
+	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
+)
 
 type GoLineCommentTrimmer struct{}
 
@@ -9,3 +13,88 @@ func (GoLineCommentTrimmer) Trim(line string) string {
 	s = strings.TrimPrefix(s, "//")
 	return strings.TrimSpace(s)
 }
+
+// GoBlockCommentTrimmer removes the /* and */ delimiters and, in a comment using continuation stars, the
+// leading '*' of its lines, so that a line of a block comment trims to the same text as the equivalent //
+// line.
+type GoBlockCommentTrimmer struct {
+	// Stars is set for comments whose lines all start with a continuation star, see ContinuationStars.
+	Stars bool
+}
+
+func (t GoBlockCommentTrimmer) Trim(line string) string {
+	s := strings.TrimSpace(line)
+	rest, opening := strings.CutPrefix(s, "/*")
+	s = strings.TrimSpace(strings.TrimSuffix(rest, "*/"))
+	switch {
+	case opening && s == "*":
+		// the second star of /**
+		return ""
+	case !opening && t.Stars && isContinuationStar(s):
+		return strings.TrimSpace(s[1:])
+	}
+	return s
+}
+
+// ContinuationStars returns true if every line after the first of the /* */ comment text starts with a
+// continuation star: a '*' followed by whitespace or making up the whole line. Blank lines and the
+// closing */ do not count either way.
+func ContinuationStars(text string) bool {
+	lines := strings.Split(text, "\n")
+	stars := false
+	for _, line := range lines[1:] {
+		s := strings.TrimSpace(line)
+		if s == "" || s == "*/" {
+			continue
+		}
+		if !isContinuationStar(strings.TrimSpace(strings.TrimSuffix(s, "*/"))) {
+			return false
+		}
+		stars = true
+	}
+	return stars
+}
+
+func isContinuationStar(s string) bool {
+	return s == "*" || strings.HasPrefix(s, "* ") || strings.HasPrefix(s, "*\t")
+}
+
+// GoCommentTrimmer trims // and /* */ comment lines alike. The leading '*' of a block comment line is
+// only removed by the trimmer ForComment returns, as it depends on the rest of the comment.
+type GoCommentTrimmer struct{}
+
+func (GoCommentTrimmer) Trim(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "//") {
+		return GoLineCommentTrimmer{}.Trim(line)
+	}
+	return GoBlockCommentTrimmer{}.Trim(line)
+}
+
+// ForComment returns the trimmer for the lines of the comment text, including its // or /* */ markers.
+func (GoCommentTrimmer) ForComment(text string) tp.LineTrimmer {
+	if strings.HasPrefix(text, "/*") {
+		return GoBlockCommentTrimmer{Stars: ContinuationStars(text)}
+	}
+	return GoLineCommentTrimmer{}
+}
+
+type PythonCommentTrimmer struct{}
+
+func (PythonCommentTrimmer) Trim(line string) string {
+	s := strings.TrimSpace(line)
+	s = strings.TrimLeft(s, "#")
+	return strings.TrimSpace(s)
+}
+
+// RustCommentTrimmer trims //, /// and //! comment lines.
+type RustCommentTrimmer struct{}
+
+func (RustCommentTrimmer) Trim(line string) string {
+	s := strings.TrimSpace(line)
+	for _, prefix := range []string{"///", "//!", "//"} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package line_handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoCommentTrimmerForComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    []string
+	}{
+		{
+			name:    "line comment",
+			comment: "// Ready is true\n//  - when ready",
+			want:    []string{"Ready is true", "- when ready"},
+		},
+		{
+			name:    "continuation stars",
+			comment: "/*\n * Ready is true\n *  - when ready\n *\n * +cty:condition:for=A\n */",
+			want:    []string{"", "Ready is true", "- when ready", "", "+cty:condition:for=A", ""},
+		},
+		{
+			name:    "javadoc opening",
+			comment: "/**\n * Ready is true\n */",
+			want:    []string{"", "Ready is true", ""},
+		},
+		{
+			name:    "bullets without continuation stars",
+			comment: "/*\n   Ready is true\n   * when ready\n   * when done\n*/",
+			want:    []string{"", "Ready is true", "* when ready", "* when done", ""},
+		},
+		{
+			name:    "stars used inconsistently",
+			comment: "/*\n * Ready is true\n   plain line\n * item\n */",
+			want:    []string{"", "* Ready is true", "plain line", "* item", ""},
+		},
+		{
+			name:    "star without whitespace is text",
+			comment: "/*\n *bold* text\n */",
+			want:    []string{"", "*bold* text", ""},
+		},
+		{
+			name:    "single line",
+			comment: "/* Ready is true */",
+			want:    []string{"Ready is true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmer := GoCommentTrimmer{}.ForComment(tt.comment)
+			var got []string
+			for _, line := range strings.Split(tt.comment, "\n") {
+				got = append(got, trimmer.Trim(line))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
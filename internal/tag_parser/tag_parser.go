@@ -4,17 +4,22 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
 )
 
 type DocTagType string
 
+// DocTag is the typed content of a documentation tag, each DocTagParser defines its own.
+type DocTag interface {
+	Type() DocTagType
+}
+
 type DocTagParser interface {
 	// Matches returns true if this parsers tag matches the line in the file
 	Matches(line string) bool
 	// ParseTag parses the desired values from a documentation tag
-	ParseTag(tagLine string) (map[string]string, error)
-	// ParseVariable parses the desired values from the variable that has the documentation tag
-	ParseVariable(varLine string) (map[string]string, error)
+	ParseTag(tagLine string) (DocTag, error)
 	// Type Returns this DocTags DocTagType
 	Type() DocTagType
 }
@@ -27,78 +32,177 @@ type LineTrimmer interface {
 	Trim(line string) string
 }
 
+// CommentTrimmer is implemented by LineTrimmers whose trimming depends on the comment a line is part of,
+// like the leading '*' of block comment lines. ForComment is given the whole text of the comment and
+// returns the trimmer for its lines.
+type CommentTrimmer interface {
+	ForComment(text string) LineTrimmer
+}
+
+// VariableExtractor reads the declaration a tag documents in a language that FileDocTagParser does not
+// otherwise understand. lines are the source lines following the comment holding the tag, starting with
+// the declaration or its annotations. It returns the same "const", "value" and "type" keys as
+// DocTagResult.Variable.
+type VariableExtractor interface {
+	Extract(lines []string) (map[string]string, error)
+}
+
+// CommentNormalizer rewrites the trimmed comment lines of a tagged declaration before they become its
+// description. ident is the name of the documented declaration.
+type CommentNormalizer interface {
+	Normalize(lines []string, ident string) []string
+}
+
+// NormalizerPipeline applies its normalizers in order.
+type NormalizerPipeline []CommentNormalizer
+
+func (p NormalizerPipeline) Normalize(lines []string, ident string) []string {
+	for _, n := range p {
+		lines = n.Normalize(lines, ident)
+	}
+	return lines
+}
+
 type DocTagResult struct {
-	Comment   string
-	Variable  map[string]string
-	TagValues map[string]string
-	Type      DocTagType
+	Comment  string
+	Variable map[string]string
+	Tag      DocTag
+	Type     DocTagType
+	// Group and Version are the API group and version of the package the tag was found in, empty if
+	// the parser could not tell.
+	Group   string
+	Version string
+	// Source is the declaration the tag documents.
+	Source Source
+	// FromCatalog is true for results read from a catalog file rather than found in the sources, their
+	// Source only holds the File and Line of the catalog entry.
+	FromCatalog bool
+}
+
+// Source locates a tagged declaration and identifies it in its language. Parsers fill in what they
+// know, the Go identity is empty for parsers that do not understand Go.
+type Source struct {
+	File   string
+	Line   int
+	Column int
+	// ImportPath and PackageName identify the Go package of the declaration.
+	ImportPath  string
+	PackageName string
+	// Ident is the package qualified identifier, e.g. v1alpha1.EncryptionCreationError, or
+	// v1alpha1.ZeebeClusterStatus.Ready for a struct field.
+	Ident string
+	// Kind is "const", "var" or "field".
+	Kind string
+	// TypeName is the Go type of a const or var, or the struct a field belongs to, empty if unknown.
+	TypeName string
 }
 
+// FileDocTagParser parses doc tags line by line, so it works for any language it is given the comment
+// syntax and a VariableExtractor of.
 type FileDocTagParser struct {
 	parsers        []DocTagParser
 	commentMatcher LineMatcher
 	commentTrimmer LineTrimmer
+	normalizer     CommentNormalizer
 	bulletMatcher  LineMatcher
+	extractor      VariableExtractor
 }
 
 func NewFileTagParser(
 	parsers []DocTagParser,
-	commentMatcher LineMatcher, commentTrimmer LineTrimmer, bulletMatcher LineMatcher,
+	commentMatcher LineMatcher, commentTrimmer LineTrimmer, normalizer CommentNormalizer, bulletMatcher LineMatcher,
+	extractor VariableExtractor,
 ) *FileDocTagParser {
+	if normalizer == nil {
+		normalizer = NormalizerPipeline{}
+	}
 	return &FileDocTagParser{
 		parsers:        parsers,
 		commentMatcher: commentMatcher,
 		commentTrimmer: commentTrimmer,
+		normalizer:     normalizer,
 		bulletMatcher:  bulletMatcher,
+		extractor:      extractor,
 	}
 }
 
-func (ftp *FileDocTagParser) ParseTags(filename string) ([]*DocTagResult, error) {
+// ParseTags returns the tags found in the file. A tag that cannot be parsed is reported to diags and
+// skipped, the rest of the file is still parsed.
+func (ftp *FileDocTagParser) ParseTags(filename string, diags *diagnostics.Collector) []*DocTagResult {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, err
+		diags.Errorf(diagnostics.Position{File: filename}, "%v", err)
+		return nil
 	}
 
 	lines := strings.Split(string(data), "\n")
 	var results []*DocTagResult
 
 	for i, line := range lines {
+		if !ftp.commentMatcher.Matches(line) {
+			continue
+		}
+		pos := diagnostics.Position{File: filename, Line: i + 1, Column: indentOf(line) + 1}
+		trimmer := ftp.trimmerFor(lines, i)
+		// Tags are parsed without the comment markers, so that /* +cty:... */ does not end in "*/".
+		tagLine := trimmer.Trim(line)
 		for _, parser := range ftp.parsers {
-			if parser.Matches(line) {
-				tagValues, err := parser.ParseTag(line)
+			if parser.Matches(tagLine) {
+				tag, err := parser.ParseTag(tagLine)
 				if err != nil {
-					return nil, fmt.Errorf("%s:%d\n\t%w", filename, i+1, err)
+					diags.Errorf(pos, "%v", err)
+					continue
 				}
-				variableLine, err := getFirstNonCommentLineAfter(i, lines, ftp.commentMatcher)
+				declIndex, err := getFirstNonCommentLineAfter(i, lines, ftp.commentMatcher)
 				if err != nil {
-					return nil, fmt.Errorf("%s:%d\n\t%w", filename, i+1, err)
+					diags.Errorf(pos, "%v", err)
+					continue
 				}
-				variableValues, err := parser.ParseVariable(variableLine)
+				variableValues, err := ftp.extractor.Extract(lines[declIndex:])
 				if err != nil {
-					return nil, fmt.Errorf("%s:%d\n\t%w", filename, i+1, err)
+					diags.Errorf(diagnostics.Position{File: filename, Line: declIndex + 1}, "%v", err)
+					continue
 				}
 
 				results = append(results, &DocTagResult{
 					Comment: strings.Join(
 						getSurroundingCommentLines(
-							i, lines,
-							ftp.commentMatcher, ftp.commentTrimmer, ftp.bulletMatcher,
+							i, lines, variableValues["const"],
+							ftp.commentMatcher, trimmer, ftp.normalizer, ftp.bulletMatcher,
 						), "\n",
 					),
-					Variable:  variableValues,
-					TagValues: tagValues,
-					Type:      parser.Type(),
+					Variable: variableValues,
+					Tag:      tag,
+					Type:     parser.Type(),
+					Source:   Source{File: filename, Line: declIndex + 1, Column: indentOf(lines[declIndex]) + 1},
 				})
 			}
 		}
 	}
 
-	return results, nil
+	return results
+}
+
+// trimmerFor returns the trimmer of the comment lines[i] is part of, the commentTrimmer itself unless it
+// is a CommentTrimmer.
+func (ftp *FileDocTagParser) trimmerFor(lines []string, i int) LineTrimmer {
+	ct, ok := ftp.commentTrimmer.(CommentTrimmer)
+	if !ok {
+		return ftp.commentTrimmer
+	}
+	start, end := i, i+1
+	for start > 0 && ftp.commentMatcher.Matches(lines[start-1]) {
+		start--
+	}
+	for end < len(lines) && ftp.commentMatcher.Matches(lines[end]) {
+		end++
+	}
+	return ct.ForComment(strings.TrimSpace(strings.Join(lines[start:end], "\n")))
 }
 
 func getSurroundingCommentLines(
-	aroundIndex int, lines []string,
-	commentMatcher LineMatcher, commentTrimmer LineTrimmer, bulletMatcher LineMatcher,
+	aroundIndex int, lines []string, ident string,
+	commentMatcher LineMatcher, commentTrimmer LineTrimmer, normalizer CommentNormalizer, bulletMatcher LineMatcher,
 ) []string {
 	// 1) collect comment lines before/after FROM THE FULL FILE
 	before := getCommentLinesBefore(aroundIndex, lines, commentMatcher, commentTrimmer)
@@ -109,7 +213,7 @@ func getSurroundingCommentLines(
 		before[i], before[j] = before[j], before[i]
 	}
 
-	commentLines := append(before, after...)
+	commentLines := trimBlankLines(normalizer.Normalize(append(before, after...), ident))
 
 	// 2) group non-bullet lines into paragraphs, pass bullet lines through
 	var joined []string
@@ -139,6 +243,18 @@ func getSurroundingCommentLines(
 	return joined
 }
 
+// trimBlankLines removes the empty lines at the start and end of a comment, such as the lines holding
+// only the delimiters of a block comment or those left behind by a CommentNormalizer.
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
 func getCommentLinesBefore(
 	index int, lines []string,
 	commentMatcher LineMatcher, commentTrimmer LineTrimmer,
@@ -164,7 +280,7 @@ func getCommentLinesBefore(
 		}
 		commentLines = append(commentLines, commentTrimmer.Trim(line))
 	}
-	
+
 	return commentLines
 }
 
@@ -197,9 +313,14 @@ func getCommentLinesAfter(
 	return commentLines
 }
 
-func getFirstNonCommentLineAfter(index int, lines []string, commentMatcher LineMatcher) (string, error) {
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// getFirstNonCommentLineAfter returns the index of the first line after index that is not a comment.
+func getFirstNonCommentLineAfter(index int, lines []string, commentMatcher LineMatcher) (int, error) {
 	if index >= len(lines)-1 {
-		return "", fmt.Errorf("could not find a variable declaration after tag declaration at line %d", index+1)
+		return 0, fmt.Errorf("could not find a variable declaration after tag declaration at line %d", index+1)
 	}
 
 	start := index
@@ -213,9 +334,9 @@ func getFirstNonCommentLineAfter(index int, lines []string, commentMatcher LineM
 		line = strings.TrimSpace(line)
 
 		if !commentMatcher.Matches(line) {
-			return line, nil
+			return start, nil
 		}
 	}
 
-	return "", fmt.Errorf("could not find a variable declaration after tag declaration at line %d", index+1)
+	return 0, fmt.Errorf("could not find a variable declaration after tag declaration at line %d", index+1)
 }
@@ -9,46 +9,61 @@ import (
 
 const DocTagCondition tp.DocTagType = "condition"
 
-// // +cty:condition:for=ZeebeCluster
-var reCondTag = regexp.MustCompile(
-	`^\s*//.*\+cty:condition:for\s*=\s*(?P<crd>\S+)`,
-)
+const condTagPrefix = "+cty:condition:"
+
+// // +cty:condition:for=ZeebeCluster,ZeebeGateway,polarity=negative
+var reCondTag = regexp.MustCompile(`\+cty:condition:`)
+
+// ConditionTag is a parsed +cty:condition tag.
+type ConditionTag struct {
+	// CRDs the condition is reported on, a Wildcard Kind stands for every documented CRD.
+	CRDs        []CRDRef
+	Polarity    Polarity
+	Severity    Severity
+	DisplayName string
+}
 
-// ConditionTagParser parses lines like: // +cty:condition:for=ZeebeCluster
+func (ConditionTag) Type() tp.DocTagType { return DocTagCondition }
+
+// ConditionTagParser parses lines like:
+//
+//	// +cty:condition:for=ZeebeCluster,polarity=negative,severity=Warning,displayName="Encryption ready"
+//	// +cty:condition:for=ZeebeCluster,ZeebeGateway
+//	// +cty:condition:for=zeebe.camunda.io/v1beta1/ZeebeCluster
+//	// +cty:condition:for=*
 type ConditionTagParser struct{}
 
 func (ConditionTagParser) Matches(line string) bool {
 	return reCondTag.MatchString(line)
 }
 
-func (ConditionTagParser) ParseTag(tagLine string) (map[string]string, error) {
-	m := reCondTag.FindStringSubmatch(tagLine)
-	if m == nil {
-		return nil, fmt.Errorf("missing value for +cty:condition:for")
+func (ConditionTagParser) ParseTag(tagLine string) (tp.DocTag, error) {
+	args, err := markerArgsOf(
+		tagLine, condTagPrefix,
+		[]string{"for", "polarity", "severity", "displayName"}, []string{"for"},
+	)
+	if err != nil {
+		return nil, err
 	}
-	crd := m[reCondTag.SubexpIndex("crd")]
-	if crd == "" {
-		return nil, fmt.Errorf("could not capture CRD from +cty:condition:for")
+	crds := make([]CRDRef, len(args["for"]))
+	for i, v := range args["for"] {
+		if crds[i], err = parseCRDRef(splitRef(v)); err != nil {
+			return nil, fmt.Errorf("invalid +cty:condition:for=%s: %w", v, err)
+		}
 	}
-	return map[string]string{"crd": crd}, nil
-}
-
-func (ConditionTagParser) ParseVariable(varLine string) (map[string]string, error) {
-	constName := firstIdent(varLine)
-	if constName == "" {
-		return nil, fmt.Errorf("could not parse const name from: %q", varLine)
+	polarity, err := ParsePolarity(single(args["polarity"]))
+	if err != nil {
+		return nil, err
 	}
-	lit, ok := firstStringLiteral(varLine)
-	if !ok {
-		// fallback to identifier as the Name
-		return map[string]string{
-			"const": constName,
-			"value": constName,
-		}, nil
+	severity, err := ParseSeverity(single(args["severity"]))
+	if err != nil {
+		return nil, err
 	}
-	return map[string]string{
-		"const": constName,
-		"value": lit,
+	return ConditionTag{
+		CRDs:        crds,
+		Polarity:    polarity,
+		Severity:    severity,
+		DisplayName: single(args["displayName"]),
 	}, nil
 }
 
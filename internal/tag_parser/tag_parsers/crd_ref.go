@@ -0,0 +1,72 @@
+package tag_parsers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CRDRef identifies the CRD a tag targets. A tag names either just the Kind or the fully qualified
+// <group>/<version>/<Kind>. Group and Version stay empty when not named and not inferred from the
+// package of the tag, and then match every group and version. Kind may be Wildcard.
+type CRDRef struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+// parseCRDRef parses the '/' separated segments of a CRD reference: <Kind> or <group>/<version>/<Kind>.
+func parseCRDRef(segments []string) (CRDRef, error) {
+	for _, s := range segments {
+		if s == "" || strings.ContainsAny(s, " \t") {
+			return CRDRef{}, fmt.Errorf("invalid CRD %q", strings.Join(segments, "/"))
+		}
+	}
+	switch len(segments) {
+	case 1:
+		return CRDRef{Kind: segments[0]}, nil
+	case 3:
+		return CRDRef{Group: segments[0], Version: segments[1], Kind: segments[2]}, nil
+	}
+	return CRDRef{}, fmt.Errorf("invalid CRD %q, expected <Kind> or <group>/<version>/<Kind>", strings.Join(segments, "/"))
+}
+
+// ParseCRDRef parses a CRD reference written like in for=: <Kind>, <group>/<version>/<Kind> or Wildcard.
+func ParseCRDRef(s string) (CRDRef, error) {
+	return parseCRDRef(splitRef(s))
+}
+
+// splitRef splits a for= value on '/', trimming the spaces around each segment.
+func splitRef(s string) []string {
+	segments := strings.Split(s, "/")
+	for i := range segments {
+		segments[i] = strings.TrimSpace(segments[i])
+	}
+	return segments
+}
+
+// Qualified returns the reference with an empty group and version filled in.
+func (r CRDRef) Qualified(group, version string) CRDRef {
+	if r.Group == "" && r.Version == "" {
+		r.Group, r.Version = group, version
+	}
+	return r
+}
+
+// IsPattern returns true if the reference can match more than one CRD.
+func (r CRDRef) IsPattern() bool {
+	return r.Kind == Wildcard || r.Group == "" || r.Version == ""
+}
+
+// Matches returns true if the CRD crd is one of the CRDs referenced by r.
+func (r CRDRef) Matches(crd CRDRef) bool {
+	return (r.Kind == Wildcard || r.Kind == crd.Kind) &&
+		(r.Group == "" || r.Group == crd.Group) &&
+		(r.Version == "" || r.Version == crd.Version)
+}
+
+func (r CRDRef) String() string {
+	if r.Group == "" && r.Version == "" {
+		return r.Kind
+	}
+	return r.Group + "/" + r.Version + "/" + r.Kind
+}
@@ -0,0 +1,189 @@
+package tag_parsers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// markerArg is a single key=value argument of a tag, list arguments have more than one value.
+type markerArg struct {
+	Key    string
+	Values []string
+}
+
+// parseMarkerArgs parses the comma separated arguments of a tag such as
+//
+//	for=ZeebeCluster,ZeebeGateway,polarity=negative,displayName="Encryption ready"
+//
+// Values are either bare words, which run until the next comma, or double-quoted Go strings. A value
+// that is not preceded by a key continues the list of the previous argument.
+func parseMarkerArgs(s string) ([]markerArg, error) {
+	var args []markerArg
+	seen := map[string]bool{}
+
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		segment, _, _ := strings.Cut(rest, ",")
+		if len(args) > 0 && (strings.HasPrefix(rest, `"`) || !strings.Contains(segment, "=")) {
+			prev := &args[len(args)-1]
+			value, remaining, err := scanMarkerValue(rest)
+			if err != nil {
+				return nil, fmt.Errorf("argument %q: %w", prev.Key, err)
+			}
+			prev.Values = append(prev.Values, value)
+			if rest, err = skipMarkerSeparator(remaining, prev.Key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		key, after, ok := strings.Cut(rest, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, ", \t") {
+			return nil, fmt.Errorf("expected key=value at %q", rest)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate argument %q", key)
+		}
+		seen[key] = true
+
+		value, remaining, err := scanMarkerValue(strings.TrimSpace(after))
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", key, err)
+		}
+		args = append(args, markerArg{Key: key, Values: []string{value}})
+		if rest, err = skipMarkerSeparator(remaining, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+// skipMarkerSeparator consumes the comma between two arguments, key is the argument before it.
+func skipMarkerSeparator(s, key string) (string, error) {
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return "", nil
+	}
+	if rest[0] != ',' {
+		return "", fmt.Errorf("expected ',' after argument %q, got %q", key, rest)
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if rest == "" {
+		return "", fmt.Errorf("trailing ',' after argument %q", key)
+	}
+	return rest, nil
+}
+
+// scanMarkerValue reads a single value from the start of s and returns it with the unread remainder.
+func scanMarkerValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexByte(s, ',')
+		if end < 0 {
+			end = len(s)
+		}
+		return strings.TrimSpace(s[:end]), s[end:], nil
+	}
+
+	// find the closing quote, skipping escaped characters
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted value %s", s[:i+1])
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted value %s", s)
+}
+
+// markerArgsOf parses the arguments following prefix in a tag line and checks them against the
+// allowed keys. Only the keys in lists may have more than one value. The "for" argument is always
+// required.
+func markerArgsOf(tagLine, prefix string, allowed, lists []string) (map[string][]string, error) {
+	tagName := strings.TrimSuffix(prefix, ":")
+	_, argString, ok := strings.Cut(tagLine, prefix)
+	if !ok {
+		return nil, fmt.Errorf("missing %s", tagName)
+	}
+	args, err := parseMarkerArgs(argString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", tagName, err)
+	}
+
+	values := map[string][]string{}
+	for _, arg := range args {
+		if !slices.Contains(allowed, arg.Key) {
+			return nil, fmt.Errorf(
+				"unknown argument %q for %s, expected one of: %s", arg.Key, tagName, strings.Join(allowed, ", "),
+			)
+		}
+		if len(arg.Values) > 1 && !slices.Contains(lists, arg.Key) {
+			return nil, fmt.Errorf("argument %q of %s takes a single value", arg.Key, tagName)
+		}
+		values[arg.Key] = arg.Values
+	}
+	if len(values["for"]) == 0 || slices.Contains(values["for"], "") {
+		return nil, fmt.Errorf("missing value for %sfor", prefix)
+	}
+	return values, nil
+}
+
+// single returns the only value of a non-list argument, or "" if it is not set.
+func single(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Wildcard matches every CRD or condition in a for= target.
+const Wildcard = "*"
+
+// Polarity tells whether a condition being True is the normal or the abnormal state.
+type Polarity string
+
+const (
+	// PolarityPositive conditions are normal when True, e.g. Ready.
+	PolarityPositive Polarity = "positive"
+	// PolarityNegative conditions are abnormal when True, e.g. Degraded.
+	PolarityNegative Polarity = "negative"
+)
+
+// ParsePolarity parses a polarity case-insensitively, an empty string is no polarity.
+func ParsePolarity(s string) (Polarity, error) {
+	switch p := Polarity(strings.ToLower(s)); p {
+	case "", PolarityPositive, PolarityNegative:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid polarity %q, expected positive or negative", s)
+}
+
+// Severity tells how serious a condition or reason is, using the Kubernetes event severities.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "Info"
+	SeverityWarning Severity = "Warning"
+	SeverityError   Severity = "Error"
+)
+
+// ParseSeverity parses a severity case-insensitively, an empty string is no severity.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		if strings.EqualFold(s, string(sev)) {
+			return sev, nil
+		}
+	}
+	if s == "" {
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid severity %q, expected Info, Warning or Error", s)
+}
@@ -0,0 +1,199 @@
+package tag_parsers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkerArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []markerArg
+		wantErr string
+	}{
+		{
+			name: "single argument",
+			in:   "for=ZeebeCluster",
+			want: []markerArg{{Key: "for", Values: []string{"ZeebeCluster"}}},
+		},
+		{
+			name: "list continues until the next key",
+			in:   "for=ZeebeCluster, ZeebeGateway ,polarity=negative",
+			want: []markerArg{
+				{Key: "for", Values: []string{"ZeebeCluster", "ZeebeGateway"}},
+				{Key: "polarity", Values: []string{"negative"}},
+			},
+		},
+		{
+			name: "quoted values keep commas, equals signs and escapes",
+			in:   `for=A,displayName="Ready, or \"done\" = true"`,
+			want: []markerArg{
+				{Key: "for", Values: []string{"A"}},
+				{Key: "displayName", Values: []string{`Ready, or "done" = true`}},
+			},
+		},
+		{
+			name: "a quoted value continues a list",
+			in:   `for=A,"B"`,
+			want: []markerArg{{Key: "for", Values: []string{"A", "B"}}},
+		},
+		{
+			name: "empty",
+			in:   "  ",
+		},
+		{name: "missing key", in: "ZeebeCluster", wantErr: "expected key=value"},
+		{name: "empty key", in: "=A", wantErr: "expected key=value"},
+		{name: "duplicate key", in: "for=A,for=B", wantErr: `duplicate argument "for"`},
+		{name: "trailing comma", in: "for=A,", wantErr: "trailing ','"},
+		{name: "unterminated quote", in: `for=A,displayName="Ready`, wantErr: "unterminated quoted value"},
+		{name: "text after a quoted value", in: `displayName="Ready" x`, wantErr: "expected ','"},
+		{name: "invalid escape", in: `displayName="\q"`, wantErr: "invalid quoted value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMarkerArgs(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionTagParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    ConditionTag
+		wantErr string
+	}{
+		{
+			name: "Kind",
+			line: "// +cty:condition:for=ZeebeCluster",
+			want: ConditionTag{CRDs: []CRDRef{{Kind: "ZeebeCluster"}}},
+		},
+		{
+			name: "typed metadata",
+			line: `+cty:condition:for=ZeebeCluster,polarity=Negative,severity=warning,displayName="Encryption ready"`,
+			want: ConditionTag{
+				CRDs:        []CRDRef{{Kind: "ZeebeCluster"}},
+				Polarity:    PolarityNegative,
+				Severity:    SeverityWarning,
+				DisplayName: "Encryption ready",
+			},
+		},
+		{
+			name: "several CRDs",
+			line: "+cty:condition:for=zeebe.io/v1/ZeebeCluster, ZeebeGateway",
+			want: ConditionTag{CRDs: []CRDRef{{Group: "zeebe.io", Version: "v1", Kind: "ZeebeCluster"}, {Kind: "ZeebeGateway"}}},
+		},
+		{
+			name: "wildcard",
+			line: "+cty:condition:for=*",
+			want: ConditionTag{CRDs: []CRDRef{{Kind: Wildcard}}},
+		},
+		{name: "missing for", line: "+cty:condition:polarity=negative", wantErr: "missing value for +cty:condition:for"},
+		{name: "empty for", line: "+cty:condition:for=", wantErr: "missing value for +cty:condition:for"},
+		{name: "unknown argument", line: "+cty:condition:for=A,color=red", wantErr: `unknown argument "color"`},
+		{
+			name:    "list of a single value argument",
+			line:    "+cty:condition:for=A,severity=Info,Error",
+			wantErr: "takes a single value",
+		},
+		{name: "invalid polarity", line: "+cty:condition:for=A,polarity=up", wantErr: "invalid polarity"},
+		{name: "invalid severity", line: "+cty:condition:for=A,severity=Fatal", wantErr: "invalid severity"},
+		{name: "group without version", line: "+cty:condition:for=zeebe.io/ZeebeCluster", wantErr: "expected <Kind>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConditionTagParser{}.ParseTag(tt.line)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReasonTagParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    ReasonTag
+		wantErr string
+	}{
+		{
+			name: "condition of a Kind",
+			line: `+cty:reason:for=ZeebeCluster / Ready,severity=Error,displayName="Creation failed"`,
+			want: ReasonTag{
+				Targets:     []ReasonTarget{{CRD: CRDRef{Kind: "ZeebeCluster"}, Condition: "Ready"}},
+				Severity:    SeverityError,
+				DisplayName: "Creation failed",
+			},
+		},
+		{
+			name: "condition of a qualified CRD",
+			line: "+cty:reason:for=zeebe.io/v1/ZeebeCluster/Ready",
+			want: ReasonTag{Targets: []ReasonTarget{
+				{CRD: CRDRef{Group: "zeebe.io", Version: "v1", Kind: "ZeebeCluster"}, Condition: "Ready"},
+			}},
+		},
+		{
+			name: "CRDs without a condition take the next one",
+			line: "+cty:reason:for=ZeebeCluster,ZeebeGateway/*,ZeebeBroker/Ready",
+			want: ReasonTag{Targets: []ReasonTarget{
+				{CRD: CRDRef{Kind: "ZeebeCluster"}, Condition: Wildcard},
+				{CRD: CRDRef{Kind: "ZeebeGateway"}, Condition: Wildcard},
+				{CRD: CRDRef{Kind: "ZeebeBroker"}, Condition: "Ready"},
+			}},
+		},
+		{
+			name: "every CRD",
+			line: "+cty:reason:for=*/Ready",
+			want: ReasonTag{Targets: []ReasonTarget{{CRD: CRDRef{Kind: Wildcard}, Condition: "Ready"}}},
+		},
+		{name: "CRD without a condition", line: "+cty:reason:for=ZeebeCluster", wantErr: "expected <CRD>/<Condition>"},
+		{name: "last CRD without a condition", line: "+cty:reason:for=A/Ready,B", wantErr: "expected <CRD>/<Condition>"},
+		{name: "empty condition", line: "+cty:reason:for=ZeebeCluster/", wantErr: "expected <CRD>/<Condition>"},
+		{
+			name:    "polarity is for conditions",
+			line:    "+cty:reason:for=A/Ready,polarity=negative",
+			wantErr: `unknown argument "polarity"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReasonTagParser{}.ParseTag(tt.line)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
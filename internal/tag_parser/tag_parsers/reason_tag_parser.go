@@ -3,55 +3,107 @@ package tag_parsers
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	tp "github.com/sourcehawk/cty-generator-addons/internal/tag_parser"
 )
 
 const DocTagReason tp.DocTagType = "reason"
 
-// // +cty:reason:for=ZeebeCluster/EncryptionReady
-// allows optional spaces around '=' and '/'
-var reReasonTag = regexp.MustCompile(
-	`^\s*//.*\+cty:reason:for\s*=\s*(?P<crd>[^\s/]+)\s*/\s*(?P<condition>\S+)`,
-)
+const reasonTagPrefix = "+cty:reason:"
+
+// // +cty:reason:for=ZeebeCluster,ZeebeGateway/*,severity=Error
+var reReasonTag = regexp.MustCompile(`\+cty:reason:`)
+
+// ReasonTarget is a condition a reason belongs to, the Kind and the Condition may be Wildcard.
+type ReasonTarget struct {
+	// CRD is the CRD the condition is reported on.
+	CRD CRDRef
+	// Condition is the type of the condition.
+	Condition string
+}
+
+// ReasonTag is a parsed +cty:reason tag.
+type ReasonTag struct {
+	Targets     []ReasonTarget
+	Severity    Severity
+	DisplayName string
+}
+
+func (ReasonTag) Type() tp.DocTagType { return DocTagReason }
 
-// ReasonTagParser parses lines like: // +cty:reason:for=ZeebeCluster/EncryptionReady
+// ReasonTagParser parses lines like:
+//
+//	// +cty:reason:for=ZeebeCluster/EncryptionReady,severity=Error,displayName="Creation failed"
+//	// +cty:reason:for=ZeebeCluster,ZeebeGateway/*
+//	// +cty:reason:for=*/Ready
+//	// +cty:reason:for=zeebe.camunda.io/v1beta1/ZeebeCluster/Ready
+//
+// A CRD listed without a condition takes the condition of the next target in the list, so
+// ZeebeCluster,ZeebeGateway/* targets every condition of both. Allows optional spaces around '/'
 type ReasonTagParser struct{}
 
 func (ReasonTagParser) Matches(line string) bool {
 	return reReasonTag.MatchString(line)
 }
 
-func (ReasonTagParser) ParseTag(tagLine string) (map[string]string, error) {
-	m := reReasonTag.FindStringSubmatch(tagLine)
-	if m == nil {
-		return nil, fmt.Errorf("missing value for +cty:reason:for")
+func (ReasonTagParser) ParseTag(tagLine string) (tp.DocTag, error) {
+	args, err := markerArgsOf(
+		tagLine, reasonTagPrefix,
+		[]string{"for", "severity", "displayName"}, []string{"for"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := parseReasonTargets(args["for"])
+	if err != nil {
+		return nil, err
 	}
-	crd := m[reReasonTag.SubexpIndex("crd")]
-	cond := m[reReasonTag.SubexpIndex("condition")]
-	if crd == "" || cond == "" {
-		return nil, fmt.Errorf("invalid +cty:reason:for, expected <CRD>/<Condition>")
+	severity, err := ParseSeverity(single(args["severity"]))
+	if err != nil {
+		return nil, err
 	}
-	return map[string]string{"crd": crd, "condition": cond}, nil
+	return ReasonTag{
+		Targets:     targets,
+		Severity:    severity,
+		DisplayName: single(args["displayName"]),
+	}, nil
 }
 
-func (ReasonTagParser) ParseVariable(varLine string) (map[string]string, error) {
-	constName := firstIdent(varLine)
-	if constName == "" {
-		return nil, fmt.Errorf("could not parse const name from: %q", varLine)
+func parseReasonTargets(values []string) ([]ReasonTarget, error) {
+	targets := make([]ReasonTarget, len(values))
+	pending := 0 // number of CRDs still waiting for a condition
+	for i, v := range values {
+		// <Kind> and <group>/<version>/<Kind> wait for a condition, the others end with one
+		segments := splitRef(v)
+		withCondition := len(segments) == 2 || len(segments) == 4
+		if withCondition {
+			segments = segments[:len(segments)-1]
+		}
+		crd, err := parseCRDRef(segments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid +cty:reason:for=%s, expected <CRD>/<Condition>: %w", v, err)
+		}
+		targets[i].CRD = crd
+		if !withCondition {
+			pending++
+			continue
+		}
+		cond := strings.TrimSpace(v[strings.LastIndex(v, "/")+1:])
+		if cond == "" || strings.ContainsAny(cond, " \t") {
+			return nil, fmt.Errorf("invalid +cty:reason:for=%s, expected <CRD>/<Condition>", v)
+		}
+		for j := i - pending; j <= i; j++ {
+			targets[j].Condition = cond
+		}
+		pending = 0
 	}
-	lit, ok := firstStringLiteral(varLine)
-	if !ok {
-		// fallback to identifier as the Name
-		return map[string]string{
-			"const": constName,
-			"value": constName,
-		}, nil
+	if pending > 0 {
+		return nil, fmt.Errorf(
+			"invalid +cty:reason:for=%s, expected <CRD>/<Condition>", strings.Join(values, ","),
+		)
 	}
-	return map[string]string{
-		"const": constName,
-		"value": lit,
-	}, nil
+	return targets, nil
 }
 
 func (ReasonTagParser) Type() tp.DocTagType { return DocTagReason }
@@ -0,0 +1,159 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	const numbers = "1\n2\n3\n4\n5\n6\n7\n"
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name:    "equal",
+			a:       numbers,
+			b:       numbers,
+			context: 3,
+		},
+		{
+			name:    "from empty",
+			b:       "a\nb\n",
+			context: 3,
+			want:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "to empty",
+			a:       "a\n",
+			context: 3,
+			want:    "@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:    "no newline at end of file",
+			a:       "a\nb",
+			b:       "a\nc",
+			context: 3,
+			want:    "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "newline added at end of file",
+			a:       "a",
+			b:       "a\n",
+			context: 3,
+			want:    "@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		{
+			name:    "change with context",
+			a:       numbers,
+			b:       strings.Replace(numbers, "4\n", "x\n", 1),
+			context: 1,
+			want:    "@@ -3,3 +3,3 @@\n 3\n-4\n+x\n 5\n",
+		},
+		{
+			name:    "adjacent hunks merge",
+			a:       numbers,
+			b:       "1\nx\n3\n4\ny\n6\n7\n",
+			context: 1,
+			want:    "@@ -1,6 +1,6 @@\n 1\n-2\n+x\n 3\n 4\n-5\n+y\n 6\n",
+		},
+		{
+			name:    "distant hunks stay apart",
+			a:       numbers,
+			b:       "1\nx\n3\n4\n5\ny\n7\n",
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n 1\n-2\n+x\n 3\n@@ -5,3 +5,3 @@\n 5\n-6\n+y\n 7\n",
+		},
+		{
+			name: "deletion without context",
+			a:    "1\n2\n3\n",
+			b:    "1\n3\n",
+			want: "@@ -2 +1,0 @@\n-2\n",
+		},
+		{
+			name: "insertion without context",
+			a:    "1\n3\n",
+			b:    "1\n2\n3\n",
+			want: "@@ -1,0 +2 @@\n+2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want != "" {
+				want = "--- old\n+++ new\n" + want
+			}
+			if got := Unified("old", "new", tt.a, tt.b, tt.context); got != want {
+				t.Fatalf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits int
+	}{
+		{name: "empty", edits: 0},
+		{name: "insert all", b: "abc", edits: 3},
+		{name: "delete all", a: "abc", edits: 3},
+		{name: "Myers paper example", a: "abcabba", b: "cbabac", edits: 5},
+		{name: "common prefix and suffix", a: "xaby", b: "xbay", edits: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+			ops := diff(a, b)
+
+			// the ops must turn a into b
+			var got []string
+			edits := 0
+			for _, o := range ops {
+				switch o.kind {
+				case opEqual:
+					if a[o.a] != b[o.b] {
+						t.Fatalf("equal op joins %q and %q", a[o.a], b[o.b])
+					}
+					got = append(got, a[o.a])
+				case opInsert:
+					got = append(got, b[o.b])
+					edits++
+				case opDelete:
+					edits++
+				}
+			}
+			if strings.Join(got, "") != tt.b {
+				t.Fatalf("ops give %q, want %q", strings.Join(got, ""), tt.b)
+			}
+			if edits != tt.edits {
+				t.Fatalf("%d edits, want %d", edits, tt.edits)
+			}
+		})
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		start, count int
+		want         string
+	}{
+		{0, 0, "0,0"},
+		{3, 0, "3,0"},
+		{0, 1, "1"},
+		{4, 1, "5"},
+		{0, 2, "1,2"},
+		{9, 3, "10,3"},
+	}
+	for _, tt := range tests {
+		if got := hunkRange(tt.start, tt.count); got != tt.want {
+			t.Errorf("hunkRange(%d, %d) = %q, want %q", tt.start, tt.count, got, tt.want)
+		}
+	}
+}
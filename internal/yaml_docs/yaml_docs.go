@@ -0,0 +1,46 @@
+package yaml_docs
+
+// Reading the (multi-document) YAML and JSON files given to the tool, catalogs and CRD manifests.
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"gopkg.in/yaml.v3"
+)
+
+var reYAMLErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// DecodeFile calls fn with every document of a (multi-document) YAML or JSON file. A file that cannot
+// be read or a syntax error is reported to diags, at the line the decoder names. The decoder cannot
+// recover from a syntax error, so the documents after it are skipped.
+func DecodeFile(filename string, diags *diagnostics.Collector, fn func(doc *yaml.Node)) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		diags.Errorf(diagnostics.Position{File: filename}, "%v", err)
+		return
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			pos, msg := diagnostics.Position{File: filename}, err.Error()
+			if m := reYAMLErrorLine.FindStringSubmatch(msg); m != nil {
+				pos.Line, _ = strconv.Atoi(m[1])
+				msg = m[2]
+			}
+			diags.Errorf(pos, "%s", msg)
+			return
+		}
+		fn(&node)
+	}
+}
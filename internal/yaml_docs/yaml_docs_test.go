@@ -0,0 +1,81 @@
+package yaml_docs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sourcehawk/cty-generator-addons/internal/diagnostics"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantDocs []int // line of the first key of every document passed to fn
+		wantLine int   // line of the reported error, -1 for none
+	}{
+		{
+			name:     "empty file",
+			content:  "",
+			wantLine: -1,
+		},
+		{
+			name:     "several documents",
+			content:  "a: 1\n---\nb: 2\n---\nc: 3\n",
+			wantDocs: []int{1, 3, 5},
+			wantLine: -1,
+		},
+		{
+			name:     "JSON",
+			content:  `{"a": 1}`,
+			wantDocs: []int{1},
+			wantLine: -1,
+		},
+		{
+			name:     "a syntax error ends the decoding",
+			content:  "a: 1\n---\nb: [\n---\nc: 3\n",
+			wantDocs: []int{1},
+			wantLine: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "file.yaml")
+			if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			diags := diagnostics.NewCollector()
+			var docs []int
+			DecodeFile(filename, diags, func(doc *yaml.Node) {
+				docs = append(docs, doc.Content[0].Line)
+			})
+
+			if !slices.Equal(docs, tt.wantDocs) {
+				t.Errorf("documents at lines %v, want %v", docs, tt.wantDocs)
+			}
+			got := diags.Diagnostics()
+			switch {
+			case tt.wantLine < 0 && len(got) > 0:
+				t.Errorf("unexpected diagnostics %v", got)
+			case tt.wantLine >= 0 && (len(got) != 1 || got[0].Line != tt.wantLine || got[0].File != filename):
+				t.Errorf("diagnostics %v, want one at %s:%d", got, filename, tt.wantLine)
+			case tt.wantLine >= 0 && strings.HasPrefix(got[0].Message, "yaml:"):
+				t.Errorf("message %q still names the line", got[0].Message)
+			}
+		})
+	}
+}
+
+func TestDecodeFileMissing(t *testing.T) {
+	diags := diagnostics.NewCollector()
+	DecodeFile(filepath.Join(t.TempDir(), "missing.yaml"), diags, func(*yaml.Node) {
+		t.Error("fn called for a missing file")
+	})
+	if !diags.HasErrors() {
+		t.Error("missing file not reported")
+	}
+}